@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/strength"
+	"passenger-go-cli/internal/utilities"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// accountField describes a form field of an account
+type accountField struct {
+	key        string
+	label      string
+	isPassword bool
+	isRequired bool
+	multiline  bool
+	validators []utilities.Validator
+}
+
+// Longest values the form accepts, in characters
+const (
+	maxPlatformLength   = 100
+	maxIdentifierLength = 255
+	maxURLLength        = 2048
+	maxNotesLength      = 10000
+)
+
+var accountFields = []accountField{
+	{key: "platform", label: "Platform", isRequired: true,
+		validators: []utilities.Validator{utilities.ValidateMaxLength(maxPlatformLength)}},
+	{key: "identifier", label: "Identifier", isRequired: true,
+		validators: []utilities.Validator{utilities.ValidateMaxLength(maxIdentifierLength)}},
+	{key: "url", label: "URL",
+		validators: []utilities.Validator{utilities.ValidateURL, utilities.ValidateMaxLength(maxURLLength)}},
+	{key: "notes", label: "Notes", multiline: true,
+		validators: []utilities.Validator{utilities.ValidateMaxLength(maxNotesLength)}},
+	{key: "passphrase", label: "Passphrase", isPassword: true, isRequired: true},
+}
+
+// accountInput holds account values given on the command line and which ones were given
+type accountInput struct {
+	values         map[string]string
+	provided       map[string]bool
+	overrideReason string // From --override-policy
+}
+
+func newAccountInput() *accountInput {
+	return &accountInput{
+		values:   map[string]string{},
+		provided: map[string]bool{},
+	}
+}
+
+func (input *accountInput) set(key, value string) {
+	input.values[key] = value
+	input.provided[key] = true
+}
+
+// request builds the API payload, taking missing values from fallback
+func (input *accountInput) request(fallback schemas.UpsertAccountRequest) schemas.UpsertAccountRequest {
+	pick := func(key, fallback string) string {
+		if input.provided[key] {
+			return input.values[key]
+		}
+		return fallback
+	}
+	return schemas.UpsertAccountRequest{
+		Platform:   pick("platform", fallback.Platform),
+		Identifier: pick("identifier", fallback.Identifier),
+		URL:        pick("url", fallback.URL),
+		Notes:      pick("notes", fallback.Notes),
+		Passphrase: pick("passphrase", fallback.Passphrase),
+	}
+}
+
+// accountInputFlags let create and update run without the interactive form
+func accountInputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "platform", Usage: "Platform of the account, e.g. GitHub."},
+		&cli.StringFlag{Name: "identifier", Usage: "Username or e-mail used on the platform."},
+		&cli.StringFlag{Name: "url", Usage: "Address of the platform."},
+		&cli.StringFlag{Name: "notes", Usage: "Notes about the account."},
+		&cli.BoolFlag{Name: "passphrase-stdin", Usage: "Read the passphrase from stdin."},
+		&cli.GenericFlag{
+			Name:  "generate",
+			Value: &generateLength{},
+			Usage: "Generate the passphrase, optionally with a length like --generate=24.",
+		},
+		&cli.StringFlag{
+			Name:      "from-json",
+			Usage:     "Read the account from a JSON file with platform, identifier, url, notes and passphrase, use - for stdin.",
+			TakesFile: true,
+		},
+		overridePolicyFlag(),
+	}
+}
+
+// readAccountInput collects values from --from-json, field flags, stdin and --generate
+func readAccountInput(context *cli.Context) (*accountInput, error) {
+	input := newAccountInput()
+	input.overrideReason = context.String("override-policy")
+
+	if context.String("from-json") == "-" && context.Bool("passphrase-stdin") {
+		return nil, cli.Exit("--from-json - and --passphrase-stdin cannot both read stdin", 1)
+	}
+	if context.IsSet("generate") && context.Bool("passphrase-stdin") {
+		return nil, cli.Exit("--generate and --passphrase-stdin cannot be used together", 1)
+	}
+
+	if path := context.String("from-json"); path != "" {
+		err := readAccountJSON(path, input)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, field := range accountFields {
+		if field.key != "passphrase" && context.IsSet(field.key) {
+			input.set(field.key, context.String(field.key))
+		}
+	}
+
+	if context.Bool("passphrase-stdin") {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, cli.Exit("Failed to read passphrase from stdin: "+err.Error(), 1)
+		}
+		// Only the line ending from `echo` or a file is dropped, other whitespace is kept
+		passphrase := strings.TrimSuffix(strings.TrimSuffix(string(content), "\n"), "\r")
+		if passphrase == "" {
+			return nil, cli.Exit("Passphrase read from stdin is empty", 1)
+		}
+		input.set("passphrase", passphrase)
+	}
+
+	if generate, ok := context.Generic("generate").(*generateLength); ok && generate.length > 0 {
+		passphrase, err := api.GeneratePassphrase(generate.length)
+		if err != nil {
+			return nil, cli.Exit("Failed to generate passphrase: "+err.Error(), 1)
+		}
+		input.set("passphrase", passphrase)
+	}
+
+	return input, nil
+}
+
+func readAccountJSON(path string, input *accountInput) error {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return cli.Exit("Failed to read JSON: "+err.Error(), 1)
+	}
+
+	// Decode strictly to catch typos, then again to learn which keys are present
+	var account schemas.UpsertAccountRequest
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&account); err != nil {
+		return cli.Exit("Invalid account JSON: "+err.Error(), 1)
+	}
+
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(content, &present); err != nil {
+		return cli.Exit("Invalid account JSON: "+err.Error(), 1)
+	}
+
+	values := map[string]string{
+		"platform":   account.Platform,
+		"identifier": account.Identifier,
+		"url":        account.URL,
+		"notes":      account.Notes,
+		"passphrase": account.Passphrase,
+	}
+	// The strict decode ignores case, a key like "Platform" would pass it
+	// and then be missed below
+	for key := range present {
+		if _, ok := values[key]; !ok {
+			return cli.Exit(fmt.Sprintf("Invalid account JSON: unknown key %q, keys are lowercase", key), 1)
+		}
+	}
+	for key, value := range values {
+		if _, ok := present[key]; ok {
+			input.set(key, value)
+		}
+	}
+	return nil
+}
+
+// completeWithForm asks for the given fields on a terminal, keeping values as defaults
+func completeWithForm(fields []accountField, values map[string]string) (map[string]string, error) {
+	if !utilities.IsTerminal(os.Stdin) {
+		var missing []string
+		for _, field := range fields {
+			missing = append(missing, "--"+field.key)
+		}
+		return nil, cli.Exit("Missing "+strings.Join(missing, ", ")+" and stdin is not a terminal to ask for them", 1)
+	}
+
+	form := utilities.NewInteractiveForm()
+	for _, field := range fields {
+		form.AddFieldWithDefault(field.key, field.label, values[field.key], field.isPassword, field.isRequired)
+		form.AddValidators(field.key, field.validators...)
+		if field.multiline {
+			form.SetMultiline(field.key)
+		}
+		if field.isPassword {
+			addPassphraseHelpers(form, field.key)
+		}
+	}
+
+	err := form.Run()
+	if err != nil {
+		return nil, cli.Exit("Failed to collect form data: "+err.Error(), 1)
+	}
+	return form.GetValues(), nil
+}
+
+// addPassphraseHelpers lets the form generate passphrases and rate them as they are typed
+func addPassphraseHelpers(form *utilities.InteractiveForm, key string) {
+	form.AddAction(key, utilities.FormAction{
+		Key:   'g',
+		Label: "Generate",
+		Run: func(string) (string, error) {
+			return api.GeneratePassphrase(defaultPassphraseLength)
+		},
+	})
+	form.AddAction(key, utilities.FormAction{
+		Key:   't',
+		Label: "Alternate",
+		Run: func(value string) (string, error) {
+			if value == "" {
+				return "", fmt.Errorf("type a passphrase to alternate first")
+			}
+			return api.AlternatePassphrase(value)
+		},
+	})
+	form.SetStrengthMeter(key, func(value string) (int, string) {
+		result := strength.Check(value)
+		if result.Warning != "" {
+			return result.Score, result.Warning
+		}
+		return result.Score, strength.DisplayTime(result.CrackTime) + " to crack offline"
+	})
+}
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"io"
 	"os"
 	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/config"
+	"passenger-go-cli/internal/generator"
 	"passenger-go-cli/internal/utilities"
+	"path/filepath"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 )
@@ -13,20 +18,104 @@ func AlternateCommand() *cli.Command {
 		Name:    "alternate",
 		Aliases: []string{"alt", "alternative", "manipulate", "shuffle"},
 		Usage:   "Alternate characters with similar looking characters.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "map",
+				Aliases: []string{"m"},
+				Value:   "leet",
+				Usage: "The substitutions to use: " + strings.Join(generator.SubstitutionMapNames(), ", ") +
+					", a JSON file like {\"a\": \"@4\"} or the name of one in the alternates folder of the config directory.",
+			},
+			&cli.StringFlag{
+				Name:  "layout",
+				Value: "us",
+				Usage: "The keyboard layout for --map keyboard: us, uk or de.",
+			},
+			&cli.Uint64Flag{
+				Name:  "seed",
+				Usage: "Make the result reproducible, never use this for real passphrases.",
+			},
+			&cli.BoolFlag{
+				Name:  "remote",
+				Usage: "Let the server alternate the passphrase instead.",
+			},
+			copyFlag("Put the alternated passphrase on the clipboard instead of printing it.", "c"),
+			revealFlag(),
+			newlineFlag(),
+		},
 		Action: func(context *cli.Context) error {
-			passphrase, err := utilities.ReadValue("Passphrase", true, true)
+			output, err := secretOutput(context, "the alternated passphrase")
 			if err != nil {
 				return err
 			}
 
-			alternate, err := api.AlternatePassphrase(passphrase)
+			passphrase, err := readAlternateInput()
 			if err != nil {
 				return err
 			}
-			os.Stdout.WriteString("Alternate passphrase printed on stderr:\n")
-			os.Stderr.WriteString(alternate)
-			os.Stdout.WriteString("\n")
-			return nil
+
+			if context.Bool("remote") {
+				if name, ok := firstSetFlag(context, []string{"map", "layout", "seed"}); ok {
+					return cli.Exit("--"+name+" cannot be combined with --remote", 1)
+				}
+				alternate, err := api.AlternatePassphrase(passphrase)
+				if err != nil {
+					return err
+				}
+				return output.Write("the alternated passphrase", alternate)
+			}
+
+			substitutions, err := loadSubstitutionMap(context.String("map"), context.String("layout"))
+			if err != nil {
+				return cli.Exit("Failed to load substitution map: "+err.Error(), 1)
+			}
+
+			random := generator.SecureRandom()
+			if context.IsSet("seed") {
+				random = generator.SeededRandom(context.Uint64("seed"))
+			}
+
+			alternate, err := generator.Alternate(passphrase, substitutions, random)
+			if err != nil {
+				return cli.Exit("Failed to alternate passphrase: "+err.Error(), 1)
+			}
+			return output.Write("the alternated passphrase", alternate)
 		},
 	}
 }
+
+// readAlternateInput asks for the passphrase on a terminal and reads it from stdin otherwise
+func readAlternateInput() (string, error) {
+	if utilities.IsTerminal(os.Stdin) {
+		return utilities.ReadValue("Passphrase", true, true)
+	}
+
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", cli.Exit("Failed to read passphrase from stdin: "+err.Error(), 1)
+	}
+	passphrase := strings.TrimRight(string(content), "\r\n")
+	if passphrase == "" {
+		return "", cli.Exit("No passphrase given on stdin", 1)
+	}
+	return passphrase, nil
+}
+
+// loadSubstitutionMap resolves a built in map, a JSON file, or a map saved
+// as alternates/<name>.json in the config directory
+func loadSubstitutionMap(name, layout string) (generator.SubstitutionMap, error) {
+	substitutions, builtin, err := generator.BuiltinSubstitutionMap(name, layout)
+	if builtin || err != nil {
+		return substitutions, err
+	}
+
+	path := name
+	if !strings.ContainsAny(name, `/\`) && filepath.Ext(name) == "" {
+		directory, err := config.GetConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(directory, "alternates", name+".json")
+	}
+	return generator.LoadSubstitutionMap(path)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/audit"
+	"passenger-go-cli/internal/utilities"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+func AuditCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "audit",
+		Aliases: []string{"check", "health"},
+		Usage:   "Will find weak, reused and similar passphrases and incomplete accounts",
+		Flags: []cli.Flag{
+			whereFlag(),
+			&cli.IntFlag{
+				Name:  "workers",
+				Value: 8,
+				Usage: "How many passphrases to fetch at the same time.",
+			},
+			&cli.IntFlag{
+				Name:  "min-strength",
+				Value: weakStrength,
+				Usage: "Passphrases with a strength below this are weak, from 0 to 100.",
+			},
+			&cli.IntFlag{
+				Name:  "similar-distance",
+				Value: 3,
+				Usage: "Passphrases that differ in at most this many characters are similar, 0 disables the check.",
+			},
+			&cli.StringFlag{
+				Name:  "fail-on",
+				Value: "high",
+				Usage: "Exit with 1 if there are findings of this severity or higher: high, medium, low or none.",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			threshold, err := audit.ParseSeverity(context.String("fail-on"))
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			accounts, err := api.GetAccounts()
+			if err != nil {
+				return err
+			}
+			accounts, err = filterAccounts(context, accounts)
+			if err != nil {
+				return err
+			}
+			if len(accounts) == 0 {
+				os.Stderr.WriteString("No accounts to audit.\n")
+				return nil
+			}
+
+			var progress func(done, total int)
+			if utilities.IsTerminal(os.Stderr) {
+				progress = func(done, total int) {
+					fmt.Fprintf(os.Stderr, "\r\033[KFetching passphrases %d/%d", done, total)
+				}
+			}
+			passphrases, failures := audit.FetchPassphrases(accounts, context.Int("workers"), api.GetAccountPassphrase, progress)
+			if progress != nil {
+				os.Stderr.WriteString("\r\033[K")
+			}
+			for _, failure := range failures {
+				os.Stderr.WriteString("⚠️ Failed to get passphrase of " + describeAccount(failure.Account) + ": " + failure.Err.Error() + "\n")
+			}
+
+			findings, err := audit.Run(accounts, passphrases, audit.Options{
+				MinStrength:     context.Int("min-strength"),
+				SimilarDistance: context.Int("similar-distance"),
+			})
+			if err != nil {
+				return cli.Exit("Failed to audit accounts: "+err.Error(), 1)
+			}
+
+			printFindings(findings, len(accounts)-len(failures), len(failures))
+
+			if threshold > 0 {
+				for _, finding := range findings {
+					if finding.Severity >= threshold {
+						return cli.Exit("", 1)
+					}
+				}
+			}
+			// An audit that could not look at every passphrase did not pass
+			if len(failures) > 0 {
+				return cli.Exit(fmt.Sprintf("❌ %d passphrases could not be audited", len(failures)), 1)
+			}
+			return nil
+		},
+	}
+}
+
+// printFindings writes the report, most urgent first. Accounts whose
+// passphrase could not be fetched are not counted as audited.
+func printFindings(findings []audit.Finding, audited, failed int) {
+	if len(findings) == 0 {
+		if failed == 0 {
+			os.Stdout.WriteString(fmt.Sprintf("✅ No problems found in %d accounts\n", audited))
+		} else {
+			os.Stdout.WriteString(fmt.Sprintf("No problems found in %d accounts, %d could not be audited\n", audited, failed))
+		}
+		return
+	}
+
+	color := utilities.UseColor(os.Stdout)
+	colors := map[audit.Severity]string{
+		audit.High:   "\033[31m",
+		audit.Medium: "\033[33m",
+		audit.Low:    "\033[36m",
+	}
+
+	counts := map[audit.Severity]int{}
+	for _, finding := range findings {
+		counts[finding.Severity]++
+
+		label := fmt.Sprintf("[%s] %s", strings.ToUpper(finding.Severity.String()), finding.Check)
+		if color {
+			label = colors[finding.Severity] + label + "\033[0m"
+		}
+		os.Stdout.WriteString(label + ": " + finding.Message + "\n")
+		for _, account := range finding.Accounts {
+			os.Stdout.WriteString("    " + account.ID + "  " + describeAccount(account) + "\n")
+		}
+	}
+
+	os.Stdout.WriteString(fmt.Sprintf("\n❌ %d high, %d medium, %d low in %d accounts\n",
+		counts[audit.High], counts[audit.Medium], counts[audit.Low], audited))
+	if failed > 0 {
+		os.Stdout.WriteString(fmt.Sprintf("%d more accounts could not be audited\n", failed))
+	}
+}
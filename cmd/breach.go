@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/audit"
+	"passenger-go-cli/internal/breach"
+	"passenger-go-cli/internal/config"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/utilities"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BreachCheckCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "breach-check",
+		Aliases:   []string{"breach", "pwned", "hibp"},
+		Usage:     "Will check passphrases against known breaches, only the first 5 characters of their SHA-1 hash leave the machine",
+		ArgsUsage: "[account...]",
+		Flags: []cli.Flag{
+			whereFlag(),
+			&cli.StringFlag{
+				Name:  "api",
+				Usage: "Base URL of a Pwned Passwords compatible range API, e.g. a local mirror. Defaults to breach_api in the config or " + breach.DefaultRangeURL + ".",
+			},
+			&cli.StringFlag{
+				Name:      "file",
+				Aliases:   []string{"f"},
+				Usage:     "Search a downloaded SHA-1 hash file sorted by hash instead of querying an API. Defaults to breach_file in the config.",
+				TakesFile: true,
+			},
+			&cli.IntFlag{
+				Name:  "workers",
+				Value: 8,
+				Usage: "How many passphrases to fetch and check at the same time.",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			if context.IsSet("api") && context.IsSet("file") {
+				return cli.Exit("--api cannot be combined with --file", 1)
+			}
+
+			checker, closeChecker, err := breachChecker(context)
+			if err != nil {
+				return err
+			}
+			defer closeChecker()
+
+			accounts, err := breachCheckAccounts(context)
+			if err != nil {
+				return err
+			}
+			if len(accounts) == 0 {
+				os.Stderr.WriteString("No accounts to check.\n")
+				return nil
+			}
+
+			// Each passphrase is hashed and checked right after it is fetched, only the counts are kept
+			var progress func(done, total int)
+			if utilities.IsTerminal(os.Stderr) {
+				progress = func(done, total int) {
+					fmt.Fprintf(os.Stderr, "\r\033[KChecking passphrases %d/%d", done, total)
+				}
+			}
+			counts, failures := audit.Fetch(accounts, context.Int("workers"), func(accountID string) (int, error) {
+				passphrase, err := api.GetAccountPassphrase(accountID)
+				if err != nil {
+					return 0, err
+				}
+				return checker.Count(breach.Hash(passphrase))
+			}, progress)
+			if progress != nil {
+				os.Stderr.WriteString("\r\033[K")
+			}
+			for _, failure := range failures {
+				os.Stderr.WriteString("⚠️ Failed to check " + describeAccount(failure.Account) + ": " + failure.Err.Error() + "\n")
+			}
+
+			breached := 0
+			for _, account := range accounts {
+				count := counts[account.ID]
+				if count == 0 {
+					continue
+				}
+				breached++
+				os.Stdout.WriteString(fmt.Sprintf("❌ %s [%s] appeared %d times in breaches\n", describeAccount(account), account.ID, count))
+			}
+
+			checked := len(accounts) - len(failures)
+			if breached == 0 {
+				os.Stdout.WriteString(fmt.Sprintf("✅ None of %d checked passphrases appeared in breaches\n", checked))
+				if len(failures) > 0 {
+					return cli.Exit("", 1)
+				}
+				return nil
+			}
+			os.Stdout.WriteString(fmt.Sprintf("\n%d of %d checked passphrases appeared in breaches, change them with `passenger-go update`\n", breached, checked))
+			return cli.Exit("", 1)
+		},
+	}
+}
+
+// breachChecker picks the hash file or range API from the flags or the config
+func breachChecker(context *cli.Context) (breach.Checker, func(), error) {
+	configuration, err := config.LoadConfig()
+	if err != nil {
+		return nil, nil, cli.Exit("Error loading config: "+err.Error(), 1)
+	}
+
+	path := configuration.BreachFile
+	if context.IsSet("file") {
+		path = context.String("file")
+	}
+	if path != "" && !context.IsSet("api") {
+		checker, err := breach.OpenFileChecker(path)
+		if err != nil {
+			return nil, nil, cli.Exit("Failed to open hash file: "+err.Error(), 1)
+		}
+		return checker, func() { checker.Close() }, nil
+	}
+
+	baseURL := configuration.BreachAPI
+	if context.IsSet("api") {
+		baseURL = context.String("api")
+	}
+	return breach.NewRangeChecker(baseURL), func() {}, nil
+}
+
+// breachCheckAccounts resolves the given accounts, or all accounts matching --where
+func breachCheckAccounts(context *cli.Context) ([]schemas.Account, error) {
+	if context.NArg() == 0 {
+		accounts, err := api.GetAccounts()
+		if err != nil {
+			return nil, err
+		}
+		return filterAccounts(context, accounts)
+	}
+
+	var accounts []schemas.Account
+	for _, reference := range context.Args().Slice() {
+		account, err := resolveAccount(reference)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+	return filterAccounts(context, accounts)
+}
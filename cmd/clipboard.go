@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"passenger-go-cli/internal/clipboard"
+	"passenger-go-cli/internal/config"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ClipboardClearCommand is the detached helper started by copySecret, it is
+// not meant to be run by hand
+func ClipboardClearCommand() *cli.Command {
+	return &cli.Command{
+		Name:      clipboard.HelperCommand,
+		Hidden:    true,
+		ArgsUsage: "<duration>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: clipboard.TerminalFlag},
+		},
+		Action: func(context *cli.Context) error {
+			after, err := time.ParseDuration(context.Args().First())
+			if err != nil {
+				return cli.Exit("Invalid duration: "+err.Error(), 1)
+			}
+			if context.Bool(clipboard.TerminalFlag) {
+				return clipboard.ClearTerminalLater(os.Stdout, after)
+			}
+			return clipboard.ClearLater(os.Stdin, after)
+		},
+	}
+}
+
+// copyFlag is shared by commands that can put a secret on the clipboard
+func copyFlag(usage string, aliases ...string) *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:    "copy",
+		Aliases: aliases,
+		Usage:   usage + " It is cleared after clipboard_clear in the config, 45s by default.",
+	}
+}
+
+// copySecret puts secret on the clipboard and clears it again later, what
+// names it in messages, like "the passphrase"
+func copySecret(secret, what string) error {
+	message, err := copyAndClear(secret, what)
+	if err != nil {
+		return cli.Exit("Failed to copy "+what+": "+err.Error(), 1)
+	}
+	os.Stderr.WriteString(message + "\n")
+	return nil
+}
+
+// copyAndClear is copySecret for callers that show the outcome themselves,
+// like the TUI. It returns a one line message, failing only when nothing
+// was copied.
+func copyAndClear(secret, what string) (string, error) {
+	if err := clipboard.Copy(secret); err != nil {
+		return "", err
+	}
+
+	after := clipboardClearAfter()
+	if after <= 0 {
+		return "📋 Copied " + what + " to the clipboard", nil
+	}
+
+	err := clipboard.ClearAfter(secret, after)
+	switch {
+	case err != nil:
+		return "📋 Copied " + what + " to the clipboard, ⚠️ failed to schedule clearing it: " + err.Error(), nil
+	case clipboard.Terminal():
+		return "📋 Copied " + what + " to the terminal clipboard, emptying it in " + after.String() +
+			" even if something else was copied since", nil
+	}
+	return "📋 Copied " + what + " to the clipboard, clearing it in " + after.String(), nil
+}
+
+// clipboardClearAfter reads clipboard_clear from the config
+func clipboardClearAfter() time.Duration {
+	configuration, err := config.LoadConfig()
+	if err != nil || configuration.ClipboardClear == "" {
+		return clipboard.DefaultClearAfter
+	}
+	after, err := time.ParseDuration(configuration.ClipboardClear)
+	if err != nil {
+		os.Stderr.WriteString("⚠️ Invalid clipboard_clear in the config, using " + clipboard.DefaultClearAfter.String() + "\n")
+		return clipboard.DefaultClearAfter
+	}
+	return after
+}
@@ -3,8 +3,8 @@ package cmd
 import (
 	"os"
 	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/metadata"
 	"passenger-go-cli/internal/schemas"
-	"passenger-go-cli/internal/utilities"
 
 	"github.com/urfave/cli/v2"
 )
@@ -13,34 +13,54 @@ func CreateCommand() *cli.Command {
 	return &cli.Command{
 		Name:    "create",
 		Aliases: []string{"add", "new", "insert"},
-		Usage:   "Create a new account with interactive form",
+		Usage:   "Create a new account with flags, JSON or an interactive form for what is missing",
+		Flags:   accountInputFlags(),
 		Action: func(context *cli.Context) error {
-			form := utilities.NewInteractiveForm()
-
-			form.AddField("platform", "Platform", false, true)
-			form.AddField("identifier", "Identifier", false, true)
-			form.AddField("url", "URL", false, false)
-			form.AddField("notes", "Notes", false, false)
-			form.AddField("passphrase", "Passphrase", true, true)
-
-			err := form.Run()
+			input, err := readAccountInput(context)
 			if err != nil {
-				return cli.Exit("Failed to collect form data: "+err.Error(), 1)
+				return err
 			}
+			return createAccount(input)
+		},
+	}
+}
 
-			account, err := api.CreateAccount(schemas.UpsertAccountRequest{
-				Platform:   form.GetValues()["platform"],
-				Identifier: form.GetValues()["identifier"],
-				URL:        form.GetValues()["url"],
-				Notes:      form.GetValues()["notes"],
-				Passphrase: form.GetValues()["passphrase"],
-			})
-			if err != nil {
-				return cli.Exit("Failed to create account: "+err.Error(), 1)
-			}
+// createAccountInteractively collects a new account with the form and saves it
+func createAccountInteractively() error {
+	return createAccount(newAccountInput())
+}
 
-			os.Stdout.WriteString("Account created successfully with Id: " + account.ID + "\n")
-			return nil
-		},
+// createAccount saves a new account, asking on a terminal for fields that were not given
+func createAccount(input *accountInput) error {
+	// Without any input keep the full form, otherwise only ask what is required
+	var missing []accountField
+	for _, field := range accountFields {
+		if len(input.provided) == 0 || (field.isRequired && input.values[field.key] == "") {
+			missing = append(missing, field)
+		}
+	}
+
+	if len(missing) > 0 {
+		values, err := completeWithForm(missing, input.values)
+		if err != nil {
+			return err
+		}
+		for key, value := range values {
+			input.set(key, value)
+		}
+	}
+
+	request := input.request(schemas.UpsertAccountRequest{})
+	if err := enforcePolicy("create", input.overrideReason, policyRequest("", request), request.Passphrase); err != nil {
+		return err
+	}
+
+	account, err := api.CreateAccount(request)
+	if err != nil {
+		return cli.Exit("Failed to create account: "+err.Error(), 1)
 	}
+
+	recordPassphraseChange(account.ID, metadata.SourceCreated)
+	os.Stdout.WriteString("Account created successfully with Id: " + account.ID + "\n")
+	return nil
 }
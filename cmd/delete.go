@@ -2,29 +2,117 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/utilities"
+	"strconv"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 )
 
 func DeleteCommand() *cli.Command {
 	return &cli.Command{
-		Name:    "delete",
-		Aliases: []string{"remove", "rm", "del", "kaboom", "shred"},
-		Usage:   "Will delete the account by id",
+		Name:      "delete",
+		Aliases:   []string{"remove", "rm", "del", "kaboom", "shred"},
+		Usage:     "Will delete the account by id, or every account matching --where",
+		ArgsUsage: "<account>",
+		Flags: []cli.Flag{
+			whereFlag(),
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Do not ask for confirmation, which is needed unless the full account ID is given.",
+			},
+		},
 		Action: func(context *cli.Context) error {
-			accountID := context.Args().First()
-			if accountID == "" {
-				return cli.Exit("Account ID is required", 1)
+			if context.IsSet("where") {
+				return deleteWhere(context)
+			}
+
+			reference := context.Args().First()
+			if reference == "" {
+				return cli.Exit("Account is required", 1)
+			}
+
+			account, err := resolveAccount(reference)
+			if err != nil {
+				return err
 			}
 
-			err := api.DeleteAccount(accountID)
+			// A platform or ID prefix may resolve to another account than meant
+			if !strings.EqualFold(reference, account.ID) && !context.Bool("yes") {
+				utilities.PrintTable([][]string{{account.ID, account.Platform, account.Identifier, account.URL}},
+					[]string{"ID", "Platform", "Identifier", "URL"}, true)
+				if !utilities.IsTerminal(os.Stdin) {
+					return cli.Exit("Refusing to delete without confirmation, pass the full ID or --yes to delete non-interactively", 1)
+				}
+				confirmed, err := utilities.Confirm("Delete " + describeAccount(*account) + "?")
+				if err != nil || !confirmed {
+					return cli.Exit("Nothing deleted", 1)
+				}
+			}
+
+			err = api.DeleteAccount(account.ID)
 			if err != nil {
 				return cli.Exit("Failed to delete account: "+err.Error(), 1)
 			}
+			forgetPassphraseAge(account.ID)
 
 			fmt.Println("✅ Account deleted successfully")
 			return nil
 		},
 	}
 }
+
+func deleteWhere(context *cli.Context) error {
+	accounts, err := api.GetAccounts()
+	if err != nil {
+		return err
+	}
+
+	accounts, err = filterAccounts(context, accounts)
+	if err != nil {
+		return err
+	}
+
+	if len(accounts) == 0 {
+		os.Stderr.WriteString("No accounts match the given query, nothing deleted.\n")
+		return nil
+	}
+
+	var rows [][]string
+	for _, account := range accounts {
+		rows = append(rows, []string{account.ID, account.Platform, account.Identifier, account.URL})
+	}
+	utilities.PrintTable(rows, []string{"ID", "Platform", "Identifier", "URL"}, true)
+
+	if !context.Bool("yes") {
+		if !utilities.IsTerminal(os.Stdin) {
+			return cli.Exit("Refusing to delete without confirmation, pass --yes to delete non-interactively", 1)
+		}
+		confirmed, err := utilities.Confirm("Delete " + strconv.Itoa(len(accounts)) + " accounts?")
+		if err != nil || !confirmed {
+			return cli.Exit("Nothing deleted", 1)
+		}
+	}
+
+	failed := 0
+	var deleted []string
+	for _, account := range accounts {
+		err := api.DeleteAccount(account.ID)
+		if err != nil {
+			failed++
+			os.Stderr.WriteString("❌ Failed to delete " + describeAccount(account) + ": " + err.Error() + "\n")
+			continue
+		}
+		deleted = append(deleted, account.ID)
+	}
+	forgetPassphraseAge(deleted...)
+
+	fmt.Println("✅ Deleted " + strconv.Itoa(len(accounts)-failed) + " accounts")
+	if failed > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
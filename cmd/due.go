@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/metadata"
+	"passenger-go-cli/internal/policy"
+	"passenger-go-cli/internal/utilities"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+const day = 24 * time.Hour
+
+func DueCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "due",
+		Aliases: []string{"overdue", "age"},
+		Usage:   "Will list accounts whose passphrase is older than the max_age of the password policy",
+		Flags: []cli.Flag{
+			whereFlag(),
+			&cli.StringFlag{
+				Name:  "within",
+				Usage: "Also list passphrases that become due within this time, like 14d or 2w.",
+			},
+			&cli.BoolFlag{
+				Name:    "all",
+				Aliases: []string{"a"},
+				Usage:   "List every account with its passphrase age, not only due ones.",
+			},
+			&cli.StringFlag{
+				Name: "seed",
+				Usage: "Record accounts without a known age as changed on this date, like 2021-03-01 or today. " +
+					"Use the date the vault was first used to get conservative ages.",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			var within time.Duration
+			if context.IsSet("within") {
+				var err error
+				within, err = policy.ParseAge(context.String("within"))
+				if err != nil {
+					return cli.Exit("Invalid --within: "+err.Error(), 1)
+				}
+			}
+
+			accounts, err := api.GetAccounts()
+			if err != nil {
+				return err
+			}
+			accounts, err = filterAccounts(context, accounts)
+			if err != nil {
+				return err
+			}
+
+			server, err := currentServer()
+			if err != nil {
+				return err
+			}
+			store, err := metadata.Load()
+			if err != nil {
+				return cli.Exit("Failed to load passphrase ages: "+err.Error(), 1)
+			}
+
+			if context.IsSet("seed") {
+				seeded, err := parseSeedDate(context.String("seed"))
+				if err != nil {
+					return cli.Exit("Invalid --seed: "+err.Error(), 1)
+				}
+				count := 0
+				for _, account := range accounts {
+					if store.Seed(server, account.ID, seeded) {
+						count++
+					}
+				}
+				if err := store.Save(); err != nil {
+					return cli.Exit("Failed to save passphrase ages: "+err.Error(), 1)
+				}
+				os.Stderr.WriteString(fmt.Sprintf("✅ Seeded %d accounts as changed on %s\n", count, seeded.Format(time.DateOnly)))
+			}
+
+			loaded, err := loadPolicy()
+			if err != nil {
+				return err
+			}
+
+			type dueRow struct {
+				cells   []string
+				overdue time.Duration // Negative while not due yet
+				unknown bool
+			}
+			var rows []dueRow
+			overdue, unknown := 0, 0
+			now := time.Now()
+
+			for _, account := range accounts {
+				maxAge, _ := loaded.MaxAge(policyAccount(account))
+				record, known := store.Get(server, account.ID)
+				if maxAge == 0 && !context.Bool("all") {
+					continue
+				}
+
+				row := dueRow{unknown: !known}
+				changed, age, limit, status := "unknown", "unknown", "none", ""
+				if maxAge > 0 {
+					limit = formatDays(maxAge)
+				}
+				if known {
+					changed = record.Changed.Local().Format(time.DateOnly)
+					if record.Source == metadata.SourceSeeded {
+						changed = "≤ " + changed
+					}
+					age = formatDays(now.Sub(record.Changed))
+					row.overdue = now.Sub(record.Changed) - maxAge
+				}
+
+				switch {
+				case maxAge == 0:
+				case !known:
+					status = "unknown age"
+					unknown++
+				case row.overdue > 0:
+					status = "overdue " + formatDays(row.overdue)
+					overdue++
+				case within > 0 && -row.overdue <= within:
+					status = "due in " + formatDays(-row.overdue)
+				case !context.Bool("all"):
+					continue
+				default:
+					status = "ok"
+				}
+
+				row.cells = []string{account.ID, account.Platform, account.Identifier, changed, age, limit, status}
+				rows = append(rows, row)
+			}
+
+			if len(rows) == 0 {
+				if loaded == nil || !slices.ContainsFunc(loaded.RuleSets, func(ruleSet policy.RuleSet) bool { return ruleSet.MaxAge != "" }) {
+					os.Stdout.WriteString("No max_age is set in " + policy.FileName + ", nothing can be due.\n")
+					return nil
+				}
+				os.Stdout.WriteString("✅ No passphrases are due\n")
+				return nil
+			}
+
+			// Unknown first, they may be the oldest, then the most overdue
+			slices.SortStableFunc(rows, func(a, b dueRow) int {
+				if a.unknown != b.unknown {
+					if a.unknown {
+						return -1
+					}
+					return 1
+				}
+				return int((b.overdue - a.overdue) / time.Second)
+			})
+
+			var table [][]string
+			for _, row := range rows {
+				table = append(table, row.cells)
+			}
+			utilities.PrintTable(table, []string{"ID", "Platform", "Identifier", "Changed", "Age", "Max age", "Status"})
+
+			if unknown > 0 {
+				os.Stderr.WriteString(fmt.Sprintf("%d accounts have no known age, rotate them or record a first-seen date with `passenger-go due --seed <date>`\n", unknown))
+			}
+			if overdue > 0 {
+				return cli.Exit(fmt.Sprintf("❌ %d passphrases are overdue, rotate them with `passenger-go rotate <account>`", overdue), 1)
+			}
+			return nil
+		},
+	}
+}
+
+// parseSeedDate reads a date like 2021-03-01, or today
+func parseSeedDate(text string) (time.Time, error) {
+	if strings.EqualFold(text, "today") || strings.EqualFold(text, "now") {
+		return time.Now(), nil
+	}
+	date, err := time.ParseInLocation(time.DateOnly, text, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date like 2021-03-01", text)
+	}
+	if date.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("%s is in the future", text)
+	}
+	return date, nil
+}
+
+func formatDays(duration time.Duration) string {
+	return fmt.Sprintf("%dd", int(duration/day))
+}
+
+// recordPassphraseChange keeps the age of a passphrase current. Failing to do
+// so should not fail the command that already changed the vault.
+func recordPassphraseChange(accountID, source string) {
+	server, err := currentServer()
+	if err == nil {
+		err = metadata.RecordChange(server, accountID, source)
+	}
+	if err != nil {
+		os.Stderr.WriteString("⚠️ Failed to record the passphrase age: " + err.Error() + "\n")
+	}
+}
+
+// forgetPassphraseAge drops the records of deleted accounts, with the same
+// leniency as recordPassphraseChange
+func forgetPassphraseAge(accountIDs ...string) {
+	if err := removePassphraseAges(accountIDs...); err != nil {
+		os.Stderr.WriteString("⚠️ Failed to forget the passphrase age: " + err.Error() + "\n")
+	}
+}
+
+// removePassphraseAges is forgetPassphraseAge for callers that report the error themselves
+func removePassphraseAges(accountIDs ...string) error {
+	server, err := currentServer()
+	if err != nil {
+		return err
+	}
+	store, err := metadata.Load()
+	if err != nil {
+		return err
+	}
+	for _, accountID := range accountIDs {
+		store.Remove(server, accountID)
+	}
+	return store.Save()
+}
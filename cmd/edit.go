@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/metadata"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/utilities"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// editDocument is what the user sees in the editor
+type editDocument struct {
+	Platform   string  `yaml:"platform"`
+	Identifier string  `yaml:"identifier"`
+	URL        string  `yaml:"url"`
+	Notes      string  `yaml:"notes"`
+	Passphrase *string `yaml:"passphrase,omitempty"`
+}
+
+func EditCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "edit",
+		Aliases:   []string{"vi", "open"},
+		Usage:     "Edit an account as a YAML document in $EDITOR",
+		ArgsUsage: "<account>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "passphrase",
+				Aliases: []string{"p"},
+				Usage:   "Include the passphrase in the document.",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Apply the changes without asking for confirmation.",
+			},
+			overridePolicyFlag(),
+		},
+		Action: func(context *cli.Context) error {
+			account, err := resolveAccount(context.Args().First())
+			if err != nil {
+				return err
+			}
+
+			currentPassphrase, err := api.GetAccountPassphrase(account.ID)
+			if err != nil {
+				return cli.Exit("Failed to get account passphrase: "+err.Error(), 1)
+			}
+
+			original := editDocument{
+				Platform:   account.Platform,
+				Identifier: account.Identifier,
+				URL:        account.URL,
+				Notes:      account.Notes,
+			}
+			if context.Bool("passphrase") {
+				original.Passphrase = &currentPassphrase
+			}
+
+			edited, err := editInEditor(*account, original)
+			if err != nil {
+				return err
+			}
+			if edited == nil {
+				os.Stderr.WriteString("No changes made\n")
+				return nil
+			}
+
+			changes := diffDocuments(original, *edited)
+			if len(changes) == 0 {
+				os.Stderr.WriteString("No changes made\n")
+				return nil
+			}
+
+			os.Stderr.WriteString(strings.Join(changes, "\n") + "\n")
+			if !context.Bool("yes") {
+				confirmed, err := utilities.Confirm("Apply these changes to " + describeAccount(*account) + "?")
+				if err != nil || !confirmed {
+					return cli.Exit("Nothing changed", 1)
+				}
+			}
+
+			return applyDocument(*account, currentPassphrase, original, *edited, context.String("override-policy"))
+		},
+	}
+}
+
+// editInEditor lets the user edit the document until it is valid. It returns
+// nil when the file was left untouched or emptied.
+func editInEditor(account schemas.Account, document editDocument) (*editDocument, error) {
+	directory, onTmpfs, err := utilities.SecureTempDir()
+	if err != nil {
+		return nil, cli.Exit("Failed to create temporary directory: "+err.Error(), 1)
+	}
+	defer utilities.ShredDir(directory)
+
+	if !onTmpfs && document.Passphrase != nil {
+		os.Stderr.WriteString("⚠️  No memory backed directory found, the passphrase is written to " + directory + " until the editor closes\n")
+	}
+
+	// Signals end the editor and cancel, the deferred shredding still runs
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+
+	body, err := yaml.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	header := "# Editing " + describeAccount(account) + " (" + account.ID + ")\n" +
+		"# Save and close the editor to apply, empty the file to cancel.\n"
+	content := []byte(header + string(body))
+
+	path := filepath.Join(directory, "account.yaml")
+	err = os.WriteFile(path, content, 0600)
+	if err != nil {
+		return nil, cli.Exit("Failed to write temporary file: "+err.Error(), 1)
+	}
+
+	for {
+		err = runEditor(path, interrupts)
+		if errors.Is(err, errEditInterrupted) {
+			return nil, cli.Exit("Interrupted, nothing changed", 1)
+		}
+		if err != nil {
+			return nil, cli.Exit("Editor failed: "+err.Error(), 1)
+		}
+
+		edited, err := os.ReadFile(path)
+		if err != nil {
+			return nil, cli.Exit("Failed to read temporary file: "+err.Error(), 1)
+		}
+		if bytes.Equal(edited, content) || len(bytes.TrimSpace(edited)) == 0 {
+			return nil, nil
+		}
+
+		parsed, err := parseDocument(edited, document)
+		if err == nil {
+			return parsed, nil
+		}
+
+		os.Stderr.WriteString("❌ " + err.Error() + "\n")
+		if !utilities.IsTerminal(os.Stdin) {
+			return nil, cli.Exit("Nothing changed", 1)
+		}
+		again, _ := utilities.Confirm("Open the editor again?")
+		if !again {
+			return nil, cli.Exit("Nothing changed", 1)
+		}
+	}
+}
+
+// errEditInterrupted is returned by runEditor when a signal ended the edit
+var errEditInterrupted = errors.New("interrupted")
+
+// runEditor opens $VISUAL or $EDITOR, which may carry arguments like "code --wait".
+// A signal on interrupts ends the editor and returns errEditInterrupted.
+func runEditor(path string, interrupts <-chan os.Signal) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	parts := strings.Fields(editor)
+	command := exec.Command(parts[0], append(parts[1:], path)...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	if err := command.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- command.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-interrupts:
+		// Editors in raw mode ignore a Ctrl+C, make sure it ends
+		if command.Process.Signal(syscall.SIGTERM) != nil {
+			command.Process.Kill()
+		}
+		<-done
+		return errEditInterrupted
+	}
+}
+
+// parseDocument decodes and validates the edited YAML against the original document
+func parseDocument(content []byte, original editDocument) (*editDocument, error) {
+	withPassphrase := original.Passphrase != nil
+
+	var document editDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	document.Platform = strings.TrimSpace(document.Platform)
+	document.Identifier = strings.TrimSpace(document.Identifier)
+	document.URL = strings.TrimSpace(document.URL)
+
+	if document.Platform == "" {
+		return nil, fmt.Errorf("platform: is required")
+	}
+	if document.Identifier == "" {
+		return nil, fmt.Errorf("identifier: is required")
+	}
+	// An unchanged URL is kept, whatever it looks like
+	if document.URL != original.URL {
+		if err := utilities.ValidateURL(document.URL); err != nil {
+			return nil, fmt.Errorf("url: %q %w", document.URL, err)
+		}
+	}
+	if withPassphrase && (document.Passphrase == nil || *document.Passphrase == "") {
+		return nil, fmt.Errorf("passphrase: cannot be empty")
+	}
+	if !withPassphrase && document.Passphrase != nil {
+		return nil, fmt.Errorf("passphrase: was not part of the document, use --passphrase to change it")
+	}
+
+	// YAML block scalars add a final newline the user did not mean to store,
+	// unless the original notes had it as well
+	document.Notes = strings.TrimSuffix(document.Notes, "\n")
+	if document.Notes == strings.TrimSuffix(original.Notes, "\n") {
+		document.Notes = original.Notes
+	}
+	return &document, nil
+}
+
+// diffDocuments describes changed fields, never showing passphrases
+func diffDocuments(before, after editDocument) []string {
+	var changes []string
+	compare := func(name, old, new string) {
+		if old == new {
+			return
+		}
+		for _, line := range strings.Split(old, "\n") {
+			changes = append(changes, "\033[31m- "+name+": "+line+"\033[0m")
+		}
+		for _, line := range strings.Split(new, "\n") {
+			changes = append(changes, "\033[32m+ "+name+": "+line+"\033[0m")
+		}
+	}
+
+	compare("platform", before.Platform, after.Platform)
+	compare("identifier", before.Identifier, after.Identifier)
+	compare("url", before.URL, after.URL)
+	compare("notes", before.Notes, after.Notes)
+	if before.Passphrase != nil && after.Passphrase != nil && *before.Passphrase != *after.Passphrase {
+		changes = append(changes, "\033[33m~ passphrase: changed ("+
+			fmt.Sprint(len([]rune(*after.Passphrase)))+" characters)\033[0m")
+	}
+
+	if !utilities.UseColor(os.Stderr) {
+		for index, change := range changes {
+			changes[index] = strings.NewReplacer("\033[31m", "", "\033[32m", "", "\033[33m", "", "\033[0m", "").Replace(change)
+		}
+	}
+	return changes
+}
+
+// applyDocument sends only what changed to the server
+func applyDocument(account schemas.Account, currentPassphrase string, before, after editDocument, overrideReason string) error {
+	newPassphrase := currentPassphrase
+	if after.Passphrase != nil {
+		newPassphrase = *after.Passphrase
+	}
+
+	if newPassphrase != currentPassphrase {
+		target := policyRequest(account.ID, schemas.UpsertAccountRequest{Platform: after.Platform, Identifier: after.Identifier, URL: after.URL})
+		if err := enforcePolicy("edit", overrideReason, target, newPassphrase); err != nil {
+			return err
+		}
+	}
+
+	detailsChanged := before.Platform != after.Platform ||
+		before.Identifier != after.Identifier ||
+		before.URL != after.URL ||
+		before.Notes != after.Notes
+
+	if detailsChanged {
+		err := api.UpdateAccount(account.ID, schemas.UpsertAccountRequest{
+			Platform:   after.Platform,
+			Identifier: after.Identifier,
+			URL:        after.URL,
+			Notes:      after.Notes,
+			Passphrase: newPassphrase,
+		})
+		if err != nil {
+			return cli.Exit("Failed to update account: "+err.Error(), 1)
+		}
+		os.Stdout.WriteString("✅ Account updated successfully\n")
+	}
+
+	if newPassphrase != currentPassphrase {
+		err := api.UpdateAccountPassphrase(account.ID, newPassphrase)
+		if err != nil {
+			return cli.Exit("Failed to update passphrase: "+err.Error(), 1)
+		}
+		recordPassphraseChange(account.ID, metadata.SourceUpdated)
+		os.Stdout.WriteString("✅ Passphrase updated successfully\n")
+	}
+
+	return nil
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/csv"
 	"os"
 	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/utilities"
 
 	"github.com/urfave/cli/v2"
 )
@@ -20,15 +23,27 @@ func ExportCommand() *cli.Command {
 				Required:  false,
 				TakesFile: true,
 			},
+			whereFlag(),
 		},
 		Action: func(context *cli.Context) error {
-			csvBytes, err := api.ExportCSV()
+			output := context.String("output")
+			// Every passphrase would stay in the scrollback
+			if output == "" && utilities.IsTerminal(os.Stdout) {
+				return cli.Exit("❌ Refusing to write the passphrases to the terminal, use --output <file> or pipe it", 1)
+			}
+
+			var csvBytes []byte
+			var err error
+
+			if context.IsSet("where") {
+				csvBytes, err = exportFilteredCSV(context)
+			} else {
+				csvBytes, err = api.ExportCSV()
+			}
 			if err != nil {
 				return err
 			}
 
-			output := context.String("output")
-
 			if output == "" {
 				os.Stdout.Write(csvBytes)
 				os.Stderr.WriteString("✅ Exported CSV to stdout, you can pipe it to a file.\n")
@@ -45,3 +60,31 @@ func ExportCommand() *cli.Command {
 		},
 	}
 }
+
+// exportFilteredCSV builds the Chromium CSV locally, the export endpoint cannot filter
+func exportFilteredCSV(context *cli.Context) ([]byte, error) {
+	accounts, err := api.GetAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err = filterAccounts(context, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	writer.Write([]string{"name", "url", "username", "password", "note"})
+
+	for _, account := range accounts {
+		passphrase, err := api.GetAccountPassphrase(account.ID)
+		if err != nil {
+			return nil, cli.Exit("Failed to get passphrase of "+describeAccount(account)+": "+err.Error(), 1)
+		}
+		writer.Write([]string{account.Platform, account.URL, account.Identifier, passphrase, account.Notes})
+	}
+
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
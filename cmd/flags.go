@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"passenger-go-cli/internal/query"
+	"passenger-go-cli/internal/schemas"
+	"strconv"
+
+	"github.com/urfave/cli/v2"
+)
+
+// formatFlag is shared by commands that can render their output with a Go template
+func formatFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "format",
+		Usage: "Render output with a Go template (e.g. '{{.Platform}}\\t{{.URL}}') or a template name from the config directory.",
+	}
+}
+
+// whereFlag is shared by commands that can narrow down accounts with a query
+func whereFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    "where",
+		Aliases: []string{"w"},
+		Usage:   "Only include accounts matching a query, e.g. 'platform ~ \"github\" and strength < 60'",
+	}
+}
+
+// filterAccounts applies the --where query if one was given
+func filterAccounts(c *cli.Context, accounts []schemas.Account) ([]schemas.Account, error) {
+	if !c.IsSet("where") {
+		return accounts, nil
+	}
+
+	source := c.String("where")
+	compiled, err := query.Compile(source)
+	if err != nil {
+		if queryError, ok := err.(*query.Error); ok {
+			return nil, cli.Exit("Invalid --where query:\n"+queryError.Explain(source), 1)
+		}
+		return nil, cli.Exit("Invalid --where query: "+err.Error(), 1)
+	}
+
+	return compiled.Filter(accounts), nil
+}
+
+// defaultPassphraseLength is used when a passphrase is generated without a length
+const defaultPassphraseLength = 32
+
+// generateLength is a flag value usable both as --generate and --generate=<length>
+type generateLength struct {
+	length int
+}
+
+func (value *generateLength) Set(raw string) error {
+	switch raw {
+	case "true":
+		value.length = defaultPassphraseLength
+		return nil
+	case "false":
+		value.length = 0
+		return nil
+	}
+
+	length, err := strconv.Atoi(raw)
+	if err != nil || length <= 0 {
+		return fmt.Errorf("length must be a positive number, got %q", raw)
+	}
+	value.length = length
+	return nil
+}
+
+func (value *generateLength) String() string {
+	if value == nil || value.length == 0 {
+		return ""
+	}
+	return strconv.Itoa(value.length)
+}
+
+// IsBoolFlag lets the flag package accept --generate without a value
+func (value *generateLength) IsBoolFlag() bool {
+	return true
+}
@@ -1,12 +1,29 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/generator"
+	"passenger-go-cli/internal/policy"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/strength"
+	"passenger-go-cli/internal/utilities"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 )
 
+// characterFlags only apply to the local character generator
+var characterFlags = []string{
+	"no-lower", "no-upper", "no-digits", "no-symbols",
+	"min-lower", "min-upper", "min-digits", "min-symbols",
+	"exclude-ambiguous", "alphabet", "pattern",
+}
+
+// wordFlags only apply to word passphrases
+var wordFlags = []string{"wordlist", "separator", "capitalize", "digit", "symbol"}
+
 func GenerateCommand() *cli.Command {
 	return &cli.Command{
 		Name:    "generate",
@@ -18,20 +35,256 @@ func GenerateCommand() *cli.Command {
 				Aliases: []string{"l"},
 				Usage:   "The length of the passphrase to generate. Default is 32.",
 			},
+			&cli.IntFlag{
+				Name:    "count",
+				Aliases: []string{"c"},
+				Value:   1,
+				Usage:   "How many passphrases to generate, one per line.",
+			},
+			&cli.BoolFlag{Name: "no-lower", Usage: "Leave out lowercase letters."},
+			&cli.BoolFlag{Name: "no-upper", Usage: "Leave out uppercase letters."},
+			&cli.BoolFlag{Name: "no-digits", Usage: "Leave out digits."},
+			&cli.BoolFlag{Name: "no-symbols", Usage: "Leave out symbols."},
+			&cli.IntFlag{Name: "min-lower", Usage: "At least this many lowercase letters."},
+			&cli.IntFlag{Name: "min-upper", Usage: "At least this many uppercase letters."},
+			&cli.IntFlag{Name: "min-digits", Usage: "At least this many digits."},
+			&cli.IntFlag{Name: "min-symbols", Usage: "At least this many symbols."},
+			&cli.BoolFlag{
+				Name:    "exclude-ambiguous",
+				Aliases: []string{"x"},
+				Usage:   "Leave out characters that are easily confused, like l, 1, O and 0.",
+			},
+			&cli.StringFlag{
+				Name:    "alphabet",
+				Aliases: []string{"a"},
+				Usage:   "Draw characters only from this alphabet instead of the character classes.",
+			},
+			&cli.StringFlag{
+				Name:    "pattern",
+				Aliases: []string{"p"},
+				Usage: "Generate from a pattern, e.g. 'Cvcv-9999-Cvcv'. C/c consonant, V/v vowel, A/a letter, " +
+					"9 digit, # symbol, * any, \\ escapes the next character.",
+			},
+			&cli.IntFlag{
+				Name:    "words",
+				Aliases: []string{"w"},
+				Usage:   "Generate a passphrase of this many random words instead of characters.",
+			},
+			&cli.StringFlag{
+				Name:  "wordlist",
+				Value: "long",
+				Usage: "The words to pick from: the EFF \"long\" or \"short\" list, or a file with one word per line.",
+			},
+			&cli.StringFlag{
+				Name:    "separator",
+				Aliases: []string{"s"},
+				Value:   "-",
+				Usage:   "What goes between the words.",
+			},
+			&cli.StringFlag{
+				Name:  "capitalize",
+				Value: generator.CapitalizeLower,
+				Usage: "How to capitalize the words: lower, title, upper or random.",
+			},
+			&cli.BoolFlag{Name: "digit", Usage: "Add a random digit to one of the words."},
+			&cli.BoolFlag{Name: "symbol", Usage: "Add a random symbol to one of the words."},
+			&cli.StringFlag{
+				Name:  "for",
+				Usage: "Follow the password rules configured for this account, see 'rules'.",
+			},
+			&cli.BoolFlag{
+				Name:  "remote",
+				Usage: "Let the server generate the passphrase instead, only --length and --count apply.",
+			},
+			copyFlag("Put the passphrase on the clipboard instead of printing it."),
+			revealFlag(),
+			newlineFlag(),
+			overridePolicyFlag(),
 		},
 		Action: func(c *cli.Context) error {
 
-			length := 32
+			length := defaultPassphraseLength
 			if c.IsSet("length") {
 				length = c.Int("length")
 			}
 
-			passphrase, err := api.GeneratePassphrase(length)
+			count := c.Int("count")
+			if count < 1 {
+				return cli.Exit("--count must be at least 1", 1)
+			}
+			if count > 1 && c.Bool("copy") {
+				return cli.Exit("--copy cannot be combined with --count", 1)
+			}
+			output, err := secretOutput(c, "the generated passphrase")
+			if err != nil {
+				return err
+			}
+
+			if c.IsSet("for") {
+				if name, ok := firstSetFlag(c, append(append([]string{"words", "remote"}, characterFlags...), wordFlags...)); ok {
+					return cli.Exit("--"+name+" cannot be combined with --for", 1)
+				}
+				account, err := resolveAccount(c.String("for"))
+				if err != nil {
+					return err
+				}
+				check, err := generatedPolicyCheck(policyAccount(*account), c.String("override-policy"))
+				if err != nil {
+					return err
+				}
+				return generateForAccount(*account, length, count, check, output)
+			}
+
+			// Without an account only rule sets that apply to every account are checked
+			check, err := generatedPolicyCheck(policy.Account{}, c.String("override-policy"))
 			if err != nil {
 				return err
 			}
-			os.Stdout.WriteString(passphrase)
-			return nil
+
+			if c.Bool("remote") {
+				if name, ok := firstSetFlag(c, append(append([]string{"words"}, characterFlags...), wordFlags...)); ok {
+					return cli.Exit("--"+name+" cannot be combined with --remote", 1)
+				}
+				return generateRemote(length, count, check, output)
+			}
+
+			if c.IsSet("words") {
+				if name, ok := firstSetFlag(c, append([]string{"length"}, characterFlags...)); ok {
+					return cli.Exit("--"+name+" cannot be combined with --words", 1)
+				}
+				wordlist, err := generator.LoadWordlist(c.String("wordlist"))
+				if err != nil {
+					return cli.Exit("Failed to load wordlist: "+err.Error(), 1)
+				}
+				return generateWords(generator.WordOptions{
+					Words:      c.Int("words"),
+					Wordlist:   wordlist,
+					Separator:  c.String("separator"),
+					Capitalize: c.String("capitalize"),
+					Digit:      c.Bool("digit"),
+					Symbol:     c.Bool("symbol"),
+				}, count, check, output)
+			}
+			if name, ok := firstSetFlag(c, wordFlags); ok {
+				return cli.Exit("--"+name+" needs --words", 1)
+			}
+			if c.IsSet("pattern") && c.IsSet("length") {
+				return cli.Exit("--length cannot be combined with --pattern, the pattern sets the length", 1)
+			}
+
+			options := generator.Options{
+				Length:           length,
+				Lower:            !c.Bool("no-lower"),
+				Upper:            !c.Bool("no-upper"),
+				Digits:           !c.Bool("no-digits"),
+				Symbols:          !c.Bool("no-symbols"),
+				MinLower:         c.Int("min-lower"),
+				MinUpper:         c.Int("min-upper"),
+				MinDigits:        c.Int("min-digits"),
+				MinSymbols:       c.Int("min-symbols"),
+				ExcludeAmbiguous: c.Bool("exclude-ambiguous"),
+				Alphabet:         c.String("alphabet"),
+				Pattern:          c.String("pattern"),
+			}
+			return generateLocal(options, count, check, output)
 		},
 	}
 }
+
+// firstSetFlag returns the first of names given on the command line
+func firstSetFlag(c *cli.Context, names []string) (string, bool) {
+	for _, name := range names {
+		if c.IsSet(name) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func generateLocal(options generator.Options, count int, check func(string) error, output utilities.SecretOutput) error {
+	bits, err := generator.Entropy(options)
+	if err != nil {
+		return cli.Exit("Failed to generate passphrase: "+err.Error(), 1)
+	}
+	return printGenerated(bits, count, func() (string, error) { return generator.Generate(options) }, check, output)
+}
+
+// generateForAccount follows the password rules of account, or the defaults
+// when none are configured
+func generateForAccount(account schemas.Account, length, count int, check func(string) error, output utilities.SecretOutput) error {
+	generate, bits, err := accountGenerator(account, length)
+	if err != nil {
+		return err
+	}
+	return printGenerated(bits, count, generate, check, output)
+}
+
+// accountGenerator returns a generator following the password rules of account
+// and its entropy, telling on stderr which rules are used
+func accountGenerator(account schemas.Account, length int) (func() (string, error), float64, error) {
+	rules, pattern, err := rulesForAccount(account)
+	if err != nil {
+		return nil, 0, err
+	}
+	if rules == nil {
+		os.Stderr.WriteString("No password rules match " + describeAccount(account) + ", using the defaults\n")
+		options := generator.DefaultOptions(length)
+		bits, err := generator.Entropy(options)
+		if err != nil {
+			return nil, 0, cli.Exit("Failed to generate passphrase: "+err.Error(), 1)
+		}
+		return func() (string, error) { return generator.Generate(options) }, bits, nil
+	}
+
+	os.Stderr.WriteString("Using password rules for " + pattern + ": " + rules.String() + "\n")
+	return func() (string, error) { return generator.GenerateForRules(rules, length) }, generator.RulesEntropy(rules, length), nil
+}
+
+func generateWords(options generator.WordOptions, count int, check func(string) error, output utilities.SecretOutput) error {
+	bits, err := generator.WordsEntropy(options)
+	if err != nil {
+		return cli.Exit("Failed to generate passphrase: "+err.Error(), 1)
+	}
+	return printGenerated(bits, count, func() (string, error) { return generator.GenerateWords(options) }, check, output)
+}
+
+// printGenerated prints count passphrases, the entropy estimate goes to stderr
+// so the output can be piped. check, if not nil, enforces the password policy.
+// output decides where the passphrases go.
+func printGenerated(bits float64, count int, generate func() (string, error), check func(string) error, output utilities.SecretOutput) error {
+	passphrases := make([]string, count)
+	for index := range passphrases {
+		passphrase, err := generate()
+		if err != nil {
+			return cli.Exit("Failed to generate passphrase: "+err.Error(), 1)
+		}
+		if check != nil {
+			if err := check(passphrase); err != nil {
+				return err
+			}
+		}
+		passphrases[index] = passphrase
+	}
+
+	score := strength.Score(bits)
+	os.Stderr.WriteString(fmt.Sprintf("Entropy: %.1f bits (%s), %s to crack offline\n",
+		bits, utilities.StrengthLabel(score), strength.DisplayTime(strength.CrackTimeForBits(bits))))
+	return output.Write("the generated passphrase", strings.Join(passphrases, "\n"))
+}
+
+func generateRemote(length, count int, check func(string) error, output utilities.SecretOutput) error {
+	passphrases := make([]string, count)
+	for index := range passphrases {
+		passphrase, err := api.GeneratePassphrase(length)
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(passphrase); err != nil {
+				return err
+			}
+		}
+		passphrases[index] = passphrase
+	}
+	return output.Write("the generated passphrase", strings.Join(passphrases, "\n"))
+}
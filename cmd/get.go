@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"os"
 	"passenger-go-cli/internal/api"
 	"passenger-go-cli/internal/utilities"
 	"strconv"
@@ -10,21 +11,35 @@ import (
 
 func GetCommand() *cli.Command {
 	return &cli.Command{
-		Name:    "get",
-		Aliases: []string{"fetch", "show"},
-		Usage:   "Will get the account details by id",
-		Args:    true,
+		Name:      "get",
+		Aliases:   []string{"fetch", "show"},
+		Usage:     "Will get the account details by id, id prefix, platform, platform/identifier or URL host",
+		ArgsUsage: "<account>",
+		Args:      true,
+		Flags: []cli.Flag{
+			formatFlag(),
+			copyFlag("Also put the passphrase on the clipboard.", "c"),
+		},
 		Action: func(context *cli.Context) error {
-			accountID := context.Args().First()
-			if accountID == "" {
-				return cli.Exit("Account ID is required, use `passenger-go list` to get the account ID", 1)
-			}
-
-			account, err := api.GetAccount(accountID)
+			account, err := resolveAccount(context.Args().First())
 			if err != nil {
 				return err
 			}
 
+			if context.Bool("copy") {
+				passphrase, err := api.GetAccountPassphrase(account.ID)
+				if err != nil {
+					return cli.Exit("Failed to get account passphrase: "+err.Error(), 1)
+				}
+				if err := copySecret(passphrase, "the passphrase of "+describeAccount(*account)); err != nil {
+					return err
+				}
+			}
+
+			if context.IsSet("format") {
+				return utilities.PrintTemplate(os.Stdout, context.String("format"), *account)
+			}
+
 			utilities.PrintTable([][]string{
 				{"ID", account.ID},
 				{"Platform", account.Platform},
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"passenger-go-cli/internal/config"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/utilities"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+func HooksCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "hooks",
+		Aliases: []string{"rotation-hooks"},
+		Usage:   "Manage hooks that change credentials in external systems when accounts are rotated.",
+		Subcommands: []*cli.Command{
+			{
+				Name:    "list",
+				Aliases: []string{"ls", "show"},
+				Usage:   "Show the configured rotation hooks.",
+				Action: func(context *cli.Context) error {
+					configuration, err := config.LoadConfig()
+					if err != nil {
+						return cli.Exit("Error loading config: "+err.Error(), 1)
+					}
+					if len(configuration.RotationHooks) == 0 {
+						os.Stdout.WriteString("No rotation hooks configured. Use 'hooks set <pattern> <executable>' to add some.\n")
+						return nil
+					}
+
+					rows := [][]string{}
+					for _, hook := range configuration.RotationHooks {
+						rows = append(rows, []string{hook.Pattern, hook.Command})
+					}
+					utilities.PrintTable(rows, []string{"Pattern", "Executable"})
+					return nil
+				},
+			},
+			{
+				Name:    "set",
+				Aliases: []string{"add"},
+				Usage: "Run an executable when accounts matching an ID, platform or URL host pattern are rotated. " +
+					"It gets {\"action\", \"server\", \"account\", \"old_passphrase\", \"new_passphrase\"} as JSON on stdin " +
+					"and must exit 0 once the credential was changed.",
+				ArgsUsage: "<pattern> <executable>",
+				Action: func(context *cli.Context) error {
+					if context.NArg() != 2 {
+						return cli.Exit("Usage: hooks set <pattern> <executable>", 1)
+					}
+					pattern := strings.ToLower(strings.TrimSpace(context.Args().Get(0)))
+					if _, err := path.Match(pattern, ""); err != nil {
+						return cli.Exit("Invalid pattern: "+err.Error(), 1)
+					}
+					executable, err := hookExecutable(context.Args().Get(1))
+					if err != nil {
+						return cli.Exit("Invalid executable: "+err.Error(), 1)
+					}
+					if _, err := exec.LookPath(executable); err != nil {
+						os.Stderr.WriteString("⚠️ " + executable + " is not an executable yet, rotations will fail until it is\n")
+					}
+
+					configuration, err := config.LoadConfig()
+					if err != nil {
+						return cli.Exit("Error loading config: "+err.Error(), 1)
+					}
+					entry := config.RotationHook{Pattern: pattern, Command: executable}
+					replaced := false
+					for index, existing := range configuration.RotationHooks {
+						if existing.Pattern == pattern {
+							configuration.RotationHooks[index] = entry
+							replaced = true
+						}
+					}
+					if !replaced {
+						configuration.RotationHooks = append(configuration.RotationHooks, entry)
+					}
+					if err := config.SaveConfig(configuration); err != nil {
+						return cli.Exit("Error saving config: "+err.Error(), 1)
+					}
+
+					os.Stdout.WriteString("✅ Rotation hook for " + pattern + " set to " + executable + "\n")
+					return nil
+				},
+			},
+			{
+				Name:      "remove",
+				Aliases:   []string{"rm", "delete"},
+				Usage:     "Remove the hook of a pattern.",
+				ArgsUsage: "<pattern>",
+				Action: func(context *cli.Context) error {
+					pattern := strings.ToLower(strings.TrimSpace(context.Args().First()))
+					configuration, err := config.LoadConfig()
+					if err != nil {
+						return cli.Exit("Error loading config: "+err.Error(), 1)
+					}
+
+					kept := configuration.RotationHooks[:0]
+					for _, hook := range configuration.RotationHooks {
+						if hook.Pattern != pattern {
+							kept = append(kept, hook)
+						}
+					}
+					if len(kept) == len(configuration.RotationHooks) {
+						return cli.Exit("No rotation hook for "+pattern, 1)
+					}
+					configuration.RotationHooks = kept
+					if err := config.SaveConfig(configuration); err != nil {
+						return cli.Exit("Error saving config: "+err.Error(), 1)
+					}
+
+					os.Stdout.WriteString("✅ Rotation hook for " + pattern + " removed\n")
+					return nil
+				},
+			},
+		},
+		Action: func(context *cli.Context) error {
+			return cli.Exit("Please specify 'hooks list', 'hooks set <pattern> <executable>' or 'hooks remove <pattern>'.", 1)
+		},
+	}
+}
+
+// hookForAccount returns the executable of the first hook matching account, "" when none does
+func hookForAccount(account schemas.Account) (string, error) {
+	configuration, err := config.LoadConfig()
+	if err != nil {
+		return "", cli.Exit("Error loading config: "+err.Error(), 1)
+	}
+	for _, hook := range configuration.RotationHooks {
+		// Unlike password rules, a hook can be meant for a single account
+		if matched, _ := path.Match(hook.Pattern, strings.ToLower(account.ID)); matched || accountMatches(hook.Pattern, account) {
+			return hook.Command, nil
+		}
+	}
+	return "", nil
+}
+
+// hookExecutable makes a relative path absolute, rotations may run from any
+// directory. Bare names are looked up in PATH when the hook runs, unless
+// only a file in the current directory has that name.
+func hookExecutable(executable string) (string, error) {
+	if filepath.IsAbs(executable) {
+		return executable, nil
+	}
+	if filepath.Base(executable) == executable {
+		if _, err := exec.LookPath(executable); err == nil {
+			return executable, nil
+		}
+		if _, err := os.Stat(executable); err != nil {
+			return executable, nil
+		}
+	}
+	return filepath.Abs(executable)
+}
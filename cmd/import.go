@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"os"
 	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/metadata"
+	"passenger-go-cli/internal/policy"
+	"passenger-go-cli/internal/resolver"
+	"passenger-go-cli/internal/schemas"
 	"passenger-go-cli/internal/utilities"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
@@ -22,6 +29,8 @@ func ImportCommand() *cli.Command {
 				Required:  true,
 				TakesFile: true,
 			},
+			formatFlag(),
+			overridePolicyFlag(),
 		},
 		Action: func(context *cli.Context) error {
 			filePath := context.String("file")
@@ -30,10 +39,27 @@ func ImportCommand() *cli.Command {
 				return cli.Exit("File not found: "+filePath, 1)
 			}
 
+			if err := enforceImportPolicy(filePath, context.String("override-policy")); err != nil {
+				return err
+			}
+
+			// The response has no IDs, the imported accounts are the ones that were not there before
+			existing, err := api.GetAccounts()
+			if err != nil {
+				return cli.Exit("Failed to get accounts: "+err.Error(), 1)
+			}
+
 			response, err := api.ImportCSV(filePath)
 			if err != nil {
 				return err
 			}
+			if response.SuccessCount > 0 {
+				recordImportedAccounts(existing)
+			}
+
+			if context.IsSet("format") {
+				return utilities.PrintTemplate(os.Stdout, context.String("format"), *response)
+			}
 
 			if response.SuccessCount > 0 {
 				os.Stdout.WriteString(
@@ -59,3 +85,135 @@ func ImportCommand() *cli.Command {
 		},
 	}
 }
+
+// recordImportedAccounts seeds the passphrase age of accounts not in existing.
+// Imported passphrases may be years old, they are only known to be at least
+// as old as the import.
+func recordImportedAccounts(existing []schemas.Account) {
+	err := seedImportedAccounts(existing)
+	if err != nil {
+		os.Stderr.WriteString("⚠️ Failed to record the passphrase age: " + err.Error() + "\n")
+	}
+}
+
+func seedImportedAccounts(existing []schemas.Account) error {
+	server, err := currentServer()
+	if err != nil {
+		return err
+	}
+	accounts, err := api.GetAccounts()
+	if err != nil {
+		return err
+	}
+	store, err := metadata.Load()
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(existing))
+	for _, account := range existing {
+		known[account.ID] = true
+	}
+	now := time.Now()
+	for _, account := range accounts {
+		if !known[account.ID] {
+			store.Seed(server, account.ID, now)
+		}
+	}
+	return store.Save()
+}
+
+// enforceImportPolicy checks every row of the CSV before anything is uploaded,
+// one violation stops the whole import
+func enforceImportPolicy(filePath, overrideReason string) error {
+	loaded, err := loadPolicy()
+	if err != nil || loaded == nil {
+		return err
+	}
+
+	rows, err := readImportRows(filePath)
+	if err != nil {
+		return err
+	}
+
+	var peers []policy.Peer
+	for _, row := range rows {
+		if loaded.NeedsPeers(row.Account) {
+			peers, err = policyPeers(loaded)
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	violating := 0
+	for _, row := range rows {
+		violations := loaded.Check(row.Account, row.Passphrase, peers)
+		// Later rows must not reuse passphrases of earlier ones either
+		peers = append(peers, row)
+		if len(violations) == 0 {
+			continue
+		}
+
+		if strings.TrimSpace(overrideReason) == "" {
+			violating++
+			os.Stderr.WriteString("❌ " + violationMessage(row.Account, violations) + "\n")
+			continue
+		}
+		if err := reportViolations("import", overrideReason, row.Account, violations); err != nil {
+			return err
+		}
+	}
+
+	if violating > 0 {
+		return cli.Exit("❌ "+strconv.Itoa(violating)+" accounts in "+filePath+" violate the password policy, nothing was imported.\n"+overrideHint, 1)
+	}
+	return nil
+}
+
+// readImportRows reads the accounts of a Chromium or Firefox CSV by their headers
+func readImportRows(filePath string) ([]policy.Peer, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, cli.Exit("Failed to read CSV: "+err.Error(), 1)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, cli.Exit("Failed to read CSV: "+err.Error(), 1)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for index, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = index
+	}
+	if _, ok := columns["password"]; !ok {
+		return nil, cli.Exit("Cannot check "+filePath+" against the password policy, it has no password column", 1)
+	}
+	value := func(record []string, name string) string {
+		if index, ok := columns[name]; ok && index < len(record) {
+			return record[index]
+		}
+		return ""
+	}
+
+	var rows []policy.Peer
+	for _, record := range records[1:] {
+		account := policy.Account{
+			Platform:   value(record, "name"),
+			Identifier: value(record, "username"),
+			URL:        value(record, "url"),
+		}
+		// Firefox has no name column, the platform is taken from the URL
+		if account.Platform == "" {
+			account.Platform = resolver.Host(account.URL)
+		}
+		rows = append(rows, policy.Peer{Account: account, Passphrase: value(record, "password")})
+	}
+	return rows, nil
+}
@@ -13,12 +13,31 @@ func ListCommand() *cli.Command {
 		Name:    "list",
 		Aliases: []string{"ls", "show-all", "fetch-all", "get-all"},
 		Usage:   "Will list all accounts",
+		Flags: []cli.Flag{
+			formatFlag(),
+			whereFlag(),
+		},
 		Action: func(c *cli.Context) error {
 			accounts, err := api.GetAccounts()
 			if err != nil {
 				return err
 			}
 
+			unfiltered := len(accounts)
+			accounts, err = filterAccounts(c, accounts)
+			if err != nil {
+				return err
+			}
+
+			if c.IsSet("format") {
+				return utilities.PrintTemplate(os.Stdout, c.String("format"), accounts...)
+			}
+
+			if len(accounts) == 0 && unfiltered > 0 {
+				os.Stderr.WriteString("No accounts match the given query.\n")
+				return nil
+			}
+
 			if len(accounts) == 0 {
 				os.Stdout.WriteString("No accounts found, use `passenger-go create` or `passenger-go import --file=<file>` to add data.")
 				return nil
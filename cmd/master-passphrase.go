@@ -13,6 +13,7 @@ func ChangeMasterPassphraseCommand() *cli.Command {
 		Name:    "master-passphrase",
 		Aliases: []string{"change-passphrase", "change-master", "change-master-pass"},
 		Usage:   "Will change the master passphrase.",
+		Flags:   []cli.Flag{allowWeakFlag()},
 		Action: func(c *cli.Context) error {
 			// 1. Take new passphrase from user
 			passphrase, err := utilities.ReadValue("New passphrase", true, true)
@@ -20,6 +21,10 @@ func ChangeMasterPassphraseCommand() *cli.Command {
 				return cli.Exit("Failed to read passphrase: "+err.Error(), 1)
 			}
 
+			if err := checkMasterPassphrase(c, passphrase); err != nil {
+				return err
+			}
+
 			// 2. Ask API to change the master passphrase
 			err = api.ChangeMasterPassphrase(passphrase)
 			if err != nil {
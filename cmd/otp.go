@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/otp"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/utilities"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func OtpCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "otp",
+		Aliases:   []string{"totp", "2fa"},
+		Usage:     "Will print the one-time code of the otpauth:// URI in the notes of the account",
+		ArgsUsage: "<account>",
+		Flags: []cli.Flag{
+			copyFlag("Put the code on the clipboard instead of printing it.", "c"),
+			revealFlag(),
+			newlineFlag(),
+		},
+		Subcommands: []*cli.Command{
+			otpAddCommand(),
+		},
+		Action: func(context *cli.Context) error {
+			// Checked first, a HOTP code is used up below
+			output, err := secretOutput(context, "the code")
+			if err != nil {
+				return err
+			}
+
+			account, err := resolveAccount(context.Args().First())
+			if err != nil {
+				return err
+			}
+
+			uri, err := accountOtpURI(*account)
+			if err != nil {
+				return err
+			}
+			key, err := otp.Parse(uri)
+			if err != nil {
+				return cli.Exit("Invalid otpauth:// URI in the notes of "+describeAccount(*account)+": "+err.Error(), 1)
+			}
+
+			now := time.Now()
+			code := key.Code(now)
+
+			// A HOTP code is used up once shown, the next one needs the next counter
+			status := fmt.Sprintf("%ds remaining", int(key.Remaining(now)/time.Second))
+			if key.Type == otp.TypeHOTP {
+				key.Counter++
+				notes := strings.Replace(account.Notes, uri, key.URI(), 1)
+				if err := updateAccountNotes(*account, notes); err != nil {
+					return cli.Exit("Failed to save the next HOTP counter, the code was not used: "+err.Error(), 1)
+				}
+				status = fmt.Sprintf("counter %d", key.Counter-1)
+			}
+
+			if output.Copy == nil && output.Reveal == 0 && utilities.IsTerminal(os.Stderr) {
+				os.Stderr.WriteString("(" + status + ")\n")
+			}
+			return output.Write("the code ("+status+")", code)
+		},
+	}
+}
+
+func otpAddCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Add an otpauth:// URI to the notes of the account, from the secret sites show for manual entry",
+		ArgsUsage: "<account>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "uri",
+				Usage: "Add this otpauth:// URI as it is instead of asking for the secret.",
+			},
+			&cli.StringFlag{
+				Name:  "issuer",
+				Usage: "The issuer shown by authenticator apps, the platform of the account by default.",
+			},
+			&cli.StringFlag{
+				Name:  "algorithm",
+				Value: "SHA1",
+				Usage: "The hash of the codes: SHA1, SHA256 or SHA512.",
+			},
+			&cli.IntFlag{
+				Name:  "digits",
+				Value: 6,
+				Usage: "The length of the codes.",
+			},
+			&cli.IntFlag{
+				Name:  "period",
+				Value: 30,
+				Usage: "How many seconds a time-based code is valid.",
+			},
+			&cli.BoolFlag{
+				Name:  "hotp",
+				Usage: "Use counter-based codes instead of time-based ones.",
+			},
+			&cli.Uint64Flag{
+				Name:  "counter",
+				Usage: "The next counter of counter-based codes.",
+			},
+			&cli.BoolFlag{
+				Name:  "replace",
+				Usage: "Replace the otpauth:// URI already in the notes.",
+			},
+			copyFlag("Put the current code on the clipboard, to compare it with the site.", "c"),
+			revealFlag(),
+		},
+		Action: func(context *cli.Context) error {
+			// The current code is only handed out when asked for, checked before anything changes
+			var output *utilities.SecretOutput
+			if context.Bool("copy") || context.IsSet("reveal") {
+				checked, err := secretOutput(context, "the current code")
+				if err != nil {
+					return err
+				}
+				output = &checked
+			}
+
+			account, err := resolveAccount(context.Args().First())
+			if err != nil {
+				return err
+			}
+
+			existing := otp.FindURIs(account.Notes)
+			if len(existing) > 0 && !context.Bool("replace") {
+				return cli.Exit("❌ "+describeAccount(*account)+" already has an otpauth:// URI, use --replace to replace it", 1)
+			}
+
+			var uri string
+			if context.IsSet("uri") {
+				uri = context.String("uri")
+			} else {
+				// The secret is as sensitive as a passphrase, keep it out of the shell history
+				secret, err := utilities.ReadValue("Secret (base32)", true, true)
+				if err != nil {
+					return cli.Exit("Failed to read secret: "+err.Error(), 1)
+				}
+				uri, err = otpURI(context, *account, secret)
+				if err != nil {
+					return err
+				}
+			}
+
+			key, err := otp.Parse(uri)
+			if err != nil {
+				return cli.Exit("Invalid otpauth:// URI: "+err.Error(), 1)
+			}
+			uri = key.URI()
+
+			notes := strings.TrimRight(account.Notes, "\n")
+			for _, old := range existing {
+				notes = strings.TrimSpace(strings.ReplaceAll(notes, old, ""))
+			}
+			if notes != "" {
+				notes += "\n"
+			}
+			notes += uri
+
+			if err := updateAccountNotes(*account, notes); err != nil {
+				return cli.Exit("Failed to update account: "+err.Error(), 1)
+			}
+
+			os.Stdout.WriteString("✅ One-time codes added to " + describeAccount(*account) + "\n")
+			// Lets the user compare with the site before turning two-factor on
+			switch {
+			case key.Type != otp.TypeTOTP:
+				return nil
+			case output != nil:
+				return output.Write("the current code", key.Code(time.Now()))
+			}
+			os.Stdout.WriteString("Compare the current code with the site using `passenger-go otp --reveal 30 " + account.ID + "`\n")
+			return nil
+		},
+	}
+}
+
+// otpURI builds a URI from a manually entered secret and the flags
+func otpURI(context *cli.Context, account schemas.Account, secret string) (string, error) {
+	decoded, err := otp.DecodeSecret(secret)
+	if err != nil {
+		return "", cli.Exit("Invalid secret: "+err.Error(), 1)
+	}
+
+	key := otp.Key{
+		Type:      otp.TypeTOTP,
+		Issuer:    account.Platform,
+		Account:   account.Identifier,
+		Secret:    decoded,
+		Algorithm: strings.ToUpper(context.String("algorithm")),
+		Digits:    context.Int("digits"),
+		Period:    context.Int("period"),
+		Counter:   context.Uint64("counter"),
+	}
+	if context.IsSet("issuer") {
+		key.Issuer = context.String("issuer")
+	}
+	if context.Bool("hotp") {
+		key.Type = otp.TypeHOTP
+	}
+
+	// Parsing validates the algorithm, digits and period
+	uri := key.URI()
+	if _, err := otp.Parse(uri); err != nil {
+		return "", cli.Exit("Invalid one-time code settings: "+err.Error(), 1)
+	}
+	return uri, nil
+}
+
+// accountOtpURI returns the only otpauth:// URI in the notes of account
+func accountOtpURI(account schemas.Account) (string, error) {
+	uris := otp.FindURIs(account.Notes)
+	switch len(uris) {
+	case 0:
+		return "", cli.Exit("❌ No otpauth:// URI in the notes of "+describeAccount(account)+
+			", add one with `passenger-go otp add "+account.ID+"`", 1)
+	case 1:
+		return uris[0], nil
+	}
+	return "", cli.Exit("❌ The notes of "+describeAccount(account)+" have several otpauth:// URIs, keep only one", 1)
+}
+
+// updateAccountNotes saves new notes, the other fields stay as they are
+func updateAccountNotes(account schemas.Account, notes string) error {
+	passphrase, err := api.GetAccountPassphrase(account.ID)
+	if err != nil {
+		return err
+	}
+	return api.UpdateAccount(account.ID, schemas.UpsertAccountRequest{
+		Platform:   account.Platform,
+		Identifier: account.Identifier,
+		URL:        account.URL,
+		Notes:      notes,
+		Passphrase: passphrase,
+	})
+}
@@ -1,26 +1,55 @@
 package cmd
 
 import (
-	"os"
 	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/utilities"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 )
 
 func PassphraseCommand() *cli.Command {
 	return &cli.Command{
-		Name:    "passphrase",
-		Aliases: []string{"pass", "passw", "password", "pw"},
-		Usage:   "Will print the passphrase for the account",
+		Name:      "passphrase",
+		Aliases:   []string{"pass", "passw", "password", "pw"},
+		Usage:     "Will output the passphrase for the account, revealed or copied on a terminal and raw when piped",
+		ArgsUsage: "<account>",
+		Flags: []cli.Flag{
+			formatFlag(),
+			copyFlag("Put the passphrase on the clipboard instead of printing it.", "c"),
+			revealFlag(),
+			newlineFlag(),
+		},
 		Action: func(c *cli.Context) error {
-			passphrase, err := api.GetAccountPassphrase(c.Args().First())
+			output, err := secretOutput(c, "the passphrase")
+			if err != nil {
+				return err
+			}
+
+			account, err := resolveAccount(c.Args().First())
 			if err != nil {
 				return err
 			}
 
-			os.Stdout.WriteString(passphrase + "\n")
+			passphrase, err := api.GetAccountPassphrase(account.ID)
+			if err != nil {
+				return err
+			}
+
+			if c.IsSet("format") {
+				var rendered strings.Builder
+				err := utilities.PrintTemplate(&rendered, c.String("format"), schemas.AccountPassphrase{
+					ID:         account.ID,
+					Passphrase: passphrase,
+				})
+				if err != nil {
+					return err
+				}
+				passphrase = rendered.String()
+			}
 
-			return nil
+			return output.Write("the passphrase of "+describeAccount(*account), passphrase)
 		},
 	}
 }
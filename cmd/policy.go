@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"os"
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/audit"
+	"passenger-go-cli/internal/policy"
+	"passenger-go-cli/internal/schemas"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// overridePolicyFlag lets a single command save passphrases that break the policy
+func overridePolicyFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "override-policy",
+		Usage: "Save even if the passphrase violates " + policy.FileName + ", the given reason is written to " + policy.OverrideLogName + ".",
+	}
+}
+
+// loadPolicy reads the policy file, nil when there is none
+func loadPolicy() (*policy.Policy, error) {
+	loaded, err := policy.Load()
+	if err != nil {
+		return nil, cli.Exit("Failed to load password policy: "+err.Error(), 1)
+	}
+	return loaded, nil
+}
+
+func policyAccount(account schemas.Account) policy.Account {
+	return policy.Account{ID: account.ID, Platform: account.Platform, Identifier: account.Identifier, URL: account.URL}
+}
+
+func policyRequest(id string, request schemas.UpsertAccountRequest) policy.Account {
+	return policy.Account{ID: id, Platform: request.Platform, Identifier: request.Identifier, URL: request.URL}
+}
+
+// enforcePolicy checks a passphrase about to be saved for account
+func enforcePolicy(command, overrideReason string, account policy.Account, passphrase string) error {
+	loaded, err := loadPolicy()
+	if err != nil || loaded == nil {
+		return err
+	}
+
+	var peers []policy.Peer
+	if loaded.NeedsPeers(account) {
+		peers, err = policyPeers(loaded)
+		if err != nil {
+			return err
+		}
+	}
+	return reportViolations(command, overrideReason, account, loaded.Check(account, passphrase, peers))
+}
+
+// policyPeers fetches the passphrases of accounts covered by a no_reuse rule set
+func policyPeers(loaded *policy.Policy) ([]policy.Peer, error) {
+	accounts, err := api.GetAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var covered []schemas.Account
+	for _, account := range accounts {
+		if loaded.NeedsPeers(policyAccount(account)) {
+			covered = append(covered, account)
+		}
+	}
+
+	passphrases, failures := audit.FetchPassphrases(covered, 8, api.GetAccountPassphrase, nil)
+	if len(failures) > 0 {
+		return nil, cli.Exit("Failed to check passphrase reuse, could not get the passphrase of "+
+			describeAccount(failures[0].Account)+": "+failures[0].Err.Error(), 1)
+	}
+
+	peers := make([]policy.Peer, 0, len(covered))
+	for _, account := range covered {
+		peers = append(peers, policy.Peer{
+			Account:    policyAccount(account),
+			Passphrase: passphrases[account.ID],
+		})
+	}
+	return peers, nil
+}
+
+const overrideHint = "Use --override-policy \"<reason>\" to use it anyway, the override is logged."
+
+// violationMessage lists the violations of one passphrase
+func violationMessage(account policy.Account, violations []policy.Violation) string {
+	name := "The passphrase"
+	if account.Platform != "" {
+		name = "The passphrase for " + account.Platform + " (" + account.Identifier + ")"
+	}
+	var lines []string
+	for _, violation := range violations {
+		lines = append(lines, "  - "+violation.String())
+	}
+	return name + " violates the password policy:\n" + strings.Join(lines, "\n")
+}
+
+// reportViolations fails the command, or logs the override when a reason was given
+func reportViolations(command, overrideReason string, account policy.Account, violations []policy.Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	message := violationMessage(account, violations)
+	if strings.TrimSpace(overrideReason) == "" {
+		return cli.Exit("❌ "+message+"\n"+overrideHint, 1)
+	}
+
+	if err := policy.LogOverride(command, overrideReason, account, violations); err != nil {
+		return cli.Exit("Failed to log policy override: "+err.Error(), 1)
+	}
+	os.Stderr.WriteString("⚠️ " + message + "\nOverridden and logged: " + overrideReason + "\n")
+	return nil
+}
+
+// generatedPolicyCheck checks generated passphrases for account, reuse is not
+// checked as random passphrases practically never collide
+func generatedPolicyCheck(account policy.Account, overrideReason string) (func(string) error, error) {
+	loaded, err := loadPolicy()
+	if err != nil {
+		return nil, err
+	}
+	return func(passphrase string) error {
+		return reportViolations("generate", overrideReason, account, loaded.Check(account, passphrase, nil))
+	}, nil
+}
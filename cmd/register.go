@@ -13,20 +13,38 @@ func RegisterCommand() *cli.Command {
 		Name:    "register",
 		Aliases: []string{"init", "initialize"},
 		Usage:   "Initialize the passenger if not already initialized.",
+		Flags: []cli.Flag{
+			allowWeakFlag(),
+			copyFlag("Put the recovery key on the clipboard instead of printing it."),
+			revealFlag(),
+			newlineFlag(),
+		},
 		Action: func(context *cli.Context) error {
+			// The recovery key is shown only once, make sure it can be before registering
+			output, err := secretOutput(context, "the recovery key")
+			if err != nil {
+				return err
+			}
+
 			// 1. Take passphrase from user
 			passphrase, err := utilities.ReadValue("Passphrase", true, true)
 			if err != nil {
 				return err
 			}
+			if err := checkMasterPassphrase(context, passphrase); err != nil {
+				return err
+			}
 			// 2. Ask API to register the system
 			recovery, err := api.Register(passphrase)
 			if err != nil {
 				return err
 			}
-			// 3. Print recovery key to stdin and the description to stderr
+			// 3. Output the recovery key and the description to stderr
 			os.Stderr.WriteString("🚨 Register flow requires you to securely store a recovery key. This key will be required if forget your master passphrase.\n This text printed to stderr, you can redirect to a file to save the recovery key.\n\n")
-			os.Stdout.WriteString(recovery)
+			if err := output.Write("the recovery key", recovery); err != nil {
+				// Registering cannot be repeated, losing the key is worse than the scrollback
+				return cli.Exit("❌ "+err.Error()+"\nRecovery key: "+recovery, 1)
+			}
 			os.Stderr.WriteString("\n\nNext step is to validate the recovery key. Run `passenger-go validate` to validate the recovery key.\n")
 			return nil
 		},
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/resolver"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/utilities"
+
+	"github.com/urfave/cli/v2"
+)
+
+// resolveAccount turns an ID, ID prefix, platform, platform/identifier or URL
+// host into an account. Without a reference, or when it is ambiguous, a picker
+// is opened on a terminal; otherwise the command fails with the candidates.
+func resolveAccount(reference string) (*schemas.Account, error) {
+	interactive := utilities.IsTerminal(os.Stdin) && utilities.IsTerminal(os.Stderr)
+
+	if reference == "" {
+		if !interactive {
+			return nil, cli.Exit("Account is required, use `passenger-go list` to get the account ID", 1)
+		}
+		accounts, err := api.GetAccounts()
+		if err != nil {
+			return nil, err
+		}
+		return pickAccount(accounts)
+	}
+
+	account, err := resolver.Resolve(reference)
+	if err == nil {
+		return account, nil
+	}
+
+	var notFound *resolver.NotFoundError
+	if errors.As(err, &notFound) {
+		return nil, cli.Exit("❌ No account matches \""+reference+"\", use `passenger-go list` or `passenger-go search` to find it", 1)
+	}
+
+	var ambiguous *resolver.AmbiguousError
+	if !errors.As(err, &ambiguous) {
+		return nil, err
+	}
+
+	if interactive {
+		return pickAccount(ambiguous.Candidates)
+	}
+
+	printCandidates(ambiguous.Candidates)
+	return nil, cli.Exit("❌ \""+reference+"\" is ambiguous, use a longer ID prefix or platform/identifier", 1)
+}
+
+func pickAccount(accounts []schemas.Account) (*schemas.Account, error) {
+	account, err := utilities.PickAccount(accounts, "Account")
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+	return account, nil
+}
+
+func printCandidates(candidates []schemas.Account) {
+	var rows [][]string
+	for _, candidate := range candidates {
+		rows = append(rows, []string{
+			candidate.ID,
+			candidate.Platform,
+			candidate.Identifier,
+			candidate.URL,
+		})
+	}
+	utilities.PrintTable(rows, []string{"ID", "Platform", "Identifier", "URL"}, true)
+}
+
+// describeAccount gives a short human readable name for an account
+func describeAccount(account schemas.Account) string {
+	return account.Platform + " (" + account.Identifier + ")"
+}
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/config"
+	"passenger-go-cli/internal/history"
+	"passenger-go-cli/internal/hooks"
+	"passenger-go-cli/internal/metadata"
+	"passenger-go-cli/internal/policy"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/utilities"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// rotateAttempts is how often a passphrase is generated again when it breaks the policy
+const rotateAttempts = 10
+
+func RotateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rotate",
+		Aliases:   []string{"renew", "cycle"},
+		Usage:     "Replace the passphrase of an account with a generated one, keeping the old one for a rollback",
+		ArgsUsage: "<account>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "length",
+				Aliases: []string{"l"},
+				Value:   defaultPassphraseLength,
+				Usage:   "The length of the new passphrase, site password rules may limit it.",
+			},
+			copyFlag("Put the new passphrase on the clipboard to change it on the site before confirming.", "c"),
+			revealFlag(),
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Save the new passphrase without asking for confirmation.",
+			},
+			&cli.BoolFlag{
+				Name:  "rollback",
+				Usage: "Restore the passphrase replaced by the last rotation.",
+			},
+			&cli.BoolFlag{
+				Name:  "history",
+				Usage: "List the replaced passphrases kept for the account, masked.",
+			},
+			&cli.StringFlag{
+				Name:      "hook",
+				Usage:     "Change the credential in an external system with this executable first, instead of a hook from 'hooks'.",
+				TakesFile: true,
+			},
+			&cli.BoolFlag{
+				Name:  "no-hook",
+				Usage: "Only change the vault, even if a hook is configured for the account.",
+			},
+			&cli.DurationFlag{
+				Name:  "hook-timeout",
+				Value: 2 * time.Minute,
+				Usage: "How long the hook may take.",
+			},
+			overridePolicyFlag(),
+		},
+		Action: func(context *cli.Context) error {
+			if context.Bool("rollback") && context.Bool("history") {
+				return cli.Exit("--rollback cannot be combined with --history", 1)
+			}
+			if context.IsSet("hook") && context.Bool("no-hook") {
+				return cli.Exit("--hook cannot be combined with --no-hook", 1)
+			}
+
+			account, err := resolveAccount(context.Args().First())
+			if err != nil {
+				return err
+			}
+			server, err := currentServer()
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case context.Bool("history"):
+				return printHistory(server, *account)
+			case context.Bool("rollback"):
+				return rollbackAccount(context, server, *account)
+			}
+			return rotateAccount(context, server, *account)
+		},
+	}
+}
+
+// currentServer identifies the vault in the local history
+func currentServer() (string, error) {
+	configuration, err := config.LoadConfig()
+	if err != nil {
+		return "", cli.Exit("Error loading config: "+err.Error(), 1)
+	}
+	return configuration.ServerURL, nil
+}
+
+func rotateAccount(context *cli.Context, server string, account schemas.Account) error {
+	// The preview stays masked, --reveal shows the new passphrase before confirming
+	var reveal *utilities.SecretOutput
+	if context.IsSet("reveal") {
+		output, err := secretOutput(context, "the new passphrase")
+		if err != nil {
+			return err
+		}
+		reveal = &output
+	}
+
+	current, err := api.GetAccountPassphrase(account.ID)
+	if err != nil {
+		return cli.Exit("Failed to get account passphrase: "+err.Error(), 1)
+	}
+
+	generate, bits, err := accountGenerator(account, context.Int("length"))
+	if err != nil {
+		return err
+	}
+	loaded, err := loadPolicy()
+	if err != nil {
+		return err
+	}
+
+	// Random passphrases rarely break a strength rule, try a few before giving up
+	target := policyAccount(account)
+	var replacement string
+	var violations []policy.Violation
+	for range rotateAttempts {
+		replacement, err = generate()
+		if err != nil {
+			return cli.Exit("Failed to generate passphrase: "+err.Error(), 1)
+		}
+		violations = loaded.Check(target, replacement, nil)
+		if len(violations) == 0 && replacement != current {
+			break
+		}
+	}
+	if err := reportViolations("rotate", context.String("override-policy"), target, violations); err != nil {
+		return err
+	}
+
+	os.Stderr.WriteString(fmt.Sprintf("Rotating %s\n  Old: %s (%d characters)\n  New: %s (%d characters, %.0f bits)\n",
+		describeAccount(account),
+		utilities.MaskPassphrase(current), len([]rune(current)),
+		utilities.MaskPassphrase(replacement), len([]rune(replacement)), bits))
+
+	hook, err := rotationHook(context, account)
+	if err != nil {
+		return err
+	}
+	if hook != "" {
+		os.Stderr.WriteString("  Hook: " + hook + "\n")
+	}
+
+	if reveal != nil {
+		if err := reveal.Write("the new passphrase", replacement); err != nil {
+			return cli.Exit("Failed to reveal the new passphrase: "+err.Error(), 1)
+		}
+	}
+	if context.Bool("copy") {
+		if err := copySecret(replacement, "the new passphrase"); err != nil {
+			return err
+		}
+		os.Stderr.WriteString("Change it on the site now, before confirming\n")
+	}
+
+	if err := confirmRotation(context, "Save the new passphrase for "+describeAccount(account)+"?"); err != nil {
+		return err
+	}
+
+	change := passphraseChange{timeout: context.Duration("hook-timeout"), server: server, account: account, hook: hook}
+	if err := change.apply(hooks.ActionRotate, current, replacement); err != nil {
+		return err
+	}
+
+	os.Stdout.WriteString("✅ Passphrase of " + describeAccount(account) + " rotated, " +
+		"undo with `passenger-go rotate --rollback " + account.ID + "` if the site did not take it\n")
+	return nil
+}
+
+// rollbackAccount restores the newest passphrase in the history. The one it
+// replaces goes into the history itself, so a rollback can be undone too.
+func rollbackAccount(context *cli.Context, server string, account schemas.Account) error {
+	entries, err := history.List(server, account.ID)
+	if err != nil {
+		return cli.Exit("Failed to read passphrase history: "+err.Error(), 1)
+	}
+	if len(entries) == 0 {
+		return cli.Exit("❌ No previous passphrase of "+describeAccount(account)+" to roll back to", 1)
+	}
+	previous := entries[0]
+
+	current, err := api.GetAccountPassphrase(account.ID)
+	if err != nil {
+		return cli.Exit("Failed to get account passphrase: "+err.Error(), 1)
+	}
+
+	os.Stderr.WriteString(fmt.Sprintf("Rolling back %s\n  Current:  %s\n  Restored: %s (replaced %s by %s)\n",
+		describeAccount(account),
+		utilities.MaskPassphrase(current),
+		utilities.MaskPassphrase(previous.Passphrase),
+		previous.Time.Local().Format("2006-01-02 15:04"), previous.Reason))
+
+	hook, err := rotationHook(context, account)
+	if err != nil {
+		return err
+	}
+	if hook != "" {
+		os.Stderr.WriteString("  Hook:     " + hook + "\n")
+	}
+
+	if err := confirmRotation(context, "Restore the previous passphrase of "+describeAccount(account)+"?"); err != nil {
+		return err
+	}
+
+	change := passphraseChange{timeout: context.Duration("hook-timeout"), server: server, account: account, hook: hook}
+	if err := change.apply(hooks.ActionRollback, current, previous.Passphrase); err != nil {
+		return err
+	}
+
+	if err := history.Remove(previous); err != nil {
+		return cli.Exit("Passphrase restored but the history could not be updated: "+err.Error(), 1)
+	}
+
+	os.Stdout.WriteString("✅ Previous passphrase of " + describeAccount(account) + " restored\n")
+	return nil
+}
+
+func printHistory(server string, account schemas.Account) error {
+	entries, err := history.List(server, account.ID)
+	if err != nil {
+		return cli.Exit("Failed to read passphrase history: "+err.Error(), 1)
+	}
+	if len(entries) == 0 {
+		os.Stdout.WriteString("No previous passphrases of " + describeAccount(account) + " are kept\n")
+		return nil
+	}
+
+	var rows [][]string
+	for _, entry := range entries {
+		rows = append(rows, []string{
+			entry.Time.Local().Format("2006-01-02 15:04"),
+			entry.Reason,
+			utilities.MaskPassphrase(entry.Passphrase),
+		})
+	}
+	utilities.PrintTable(rows, []string{"Replaced", "By", "Passphrase"})
+	return nil
+}
+
+// confirmRotation asks before changing the vault, --yes skips it
+func confirmRotation(context *cli.Context, question string) error {
+	if context.Bool("yes") {
+		return nil
+	}
+	if !utilities.IsTerminal(os.Stdin) {
+		return cli.Exit("stdin is not a terminal to confirm, use --yes", 1)
+	}
+	confirmed, err := utilities.Confirm(question)
+	if err != nil || !confirmed {
+		return cli.Exit("Nothing changed", 1)
+	}
+	return nil
+}
+
+// rotationHook picks --hook, or the configured hook of account unless --no-hook
+func rotationHook(context *cli.Context, account schemas.Account) (string, error) {
+	switch {
+	case context.Bool("no-hook"):
+		return "", nil
+	case context.IsSet("hook"):
+		return context.String("hook"), nil
+	}
+	return hookForAccount(account)
+}
+
+// passphraseChange changes a passphrase in two phases: first in the external
+// system through the hook, then in the vault
+type passphraseChange struct {
+	timeout time.Duration // Of each hook run
+	server  string
+	account schemas.Account
+	hook    string
+}
+
+// apply only updates the vault when the hook succeeded, keeping old in the
+// history just before. When the vault update fails, or the hook timed out and
+// may have changed the credential anyway, the hook is run again to revert, so
+// both keep the same passphrase.
+func (change passphraseChange) apply(action, old, replacement string) error {
+	payload := hooks.Payload{
+		Action: action,
+		Server: change.server,
+		Account: hooks.Account{
+			ID:         change.account.ID,
+			Platform:   change.account.Platform,
+			Identifier: change.account.Identifier,
+			URL:        change.account.URL,
+		},
+		OldPassphrase: old,
+		NewPassphrase: replacement,
+	}
+
+	if change.hook != "" {
+		os.Stderr.WriteString("Running hook " + change.hook + "\n")
+		err := hooks.Run(change.hook, payload, change.timeout, os.Stderr)
+		if errors.Is(err, hooks.ErrTimeout) {
+			return change.revert(payload, err.Error())
+		}
+		if err != nil {
+			return cli.Exit("❌ "+err.Error()+", the vault was not changed", 1)
+		}
+	}
+
+	kept := change.entry(old, action)
+	if err := history.Push(kept); err != nil {
+		return change.revert(payload, "Failed to keep the old passphrase for a rollback: "+err.Error())
+	}
+
+	if err := api.UpdateAccountPassphrase(change.account.ID, replacement); err != nil {
+		history.Remove(kept)
+		return change.revert(payload, "Failed to update passphrase: "+err.Error())
+	}
+
+	source := metadata.SourceRotated
+	if action == hooks.ActionRollback {
+		source = metadata.SourceRolledBack
+	}
+	recordPassphraseChange(change.account.ID, source)
+	return nil
+}
+
+// revert undoes the hook after the vault could not be updated
+func (change passphraseChange) revert(payload hooks.Payload, failure string) error {
+	if change.hook == "" {
+		return cli.Exit(failure+", nothing changed", 1)
+	}
+
+	os.Stderr.WriteString("❌ " + failure + "\nReverting with hook " + change.hook + "\n")
+	action := payload.Action
+	payload.Action = hooks.ActionRevert
+	payload.OldPassphrase, payload.NewPassphrase = payload.NewPassphrase, payload.OldPassphrase
+	revertErr := hooks.Run(change.hook, payload, change.timeout, os.Stderr)
+	if revertErr == nil {
+		return cli.Exit("The hook reverted the change, the external system and the vault still have the old passphrase", 1)
+	}
+
+	// The external system now has a passphrase the vault does not, keep it
+	// where a rollback finds it
+	if err := history.Push(change.entry(payload.OldPassphrase, "failed "+action)); err != nil {
+		return cli.Exit("❌ Reverting failed too: "+revertErr.Error()+
+			"\nThe external system has the new passphrase of "+describeAccount(change.account)+" ["+change.account.ID+"]"+
+			", but its \"failed "+action+"\" history entry could not be saved: "+err.Error()+
+			"\nReset the passphrase on the site", 1)
+	}
+	return cli.Exit("❌ Reverting failed too: "+revertErr.Error()+
+		"\nThe external system has the new passphrase, save it with `passenger-go rotate --rollback --no-hook "+change.account.ID+"`", 1)
+}
+
+func (change passphraseChange) entry(passphrase, reason string) history.Entry {
+	return history.Entry{
+		Server:     change.server,
+		AccountID:  change.account.ID,
+		Platform:   change.account.Platform,
+		Identifier: change.account.Identifier,
+		Passphrase: passphrase,
+		Reason:     reason,
+		Time:       time.Now().UTC(),
+	}
+}
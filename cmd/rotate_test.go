@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"passenger-go-cli/internal/auth"
+	"passenger-go-cli/internal/config"
+	"passenger-go-cli/internal/history"
+	"passenger-go-cli/internal/hooks"
+	"passenger-go-cli/internal/schemas"
+
+	"github.com/zalando/go-keyring"
+)
+
+// fakeVault serves the passphrase endpoint, answering with status
+type fakeVault struct {
+	mutex   sync.Mutex
+	status  int
+	updates []string // Passphrases the client tried to save
+}
+
+func (vault *fakeVault) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPut || request.URL.Path != "/api/accounts/a1b2c3d4/passphrase" {
+		http.NotFound(writer, request)
+		return
+	}
+	var body map[string]string
+	json.NewDecoder(request.Body).Decode(&body)
+
+	vault.mutex.Lock()
+	vault.updates = append(vault.updates, body["passphrase"])
+	vault.mutex.Unlock()
+
+	writer.WriteHeader(vault.status)
+	writer.Write([]byte("{}"))
+}
+
+// setupRotation points the config at vault, with a mock keyring and a
+// temporary config directory
+func setupRotation(t *testing.T, vault *fakeVault) string {
+	t.Helper()
+	keyring.MockInit()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+
+	if err := config.SaveConfig(&config.Config{ServerURL: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.StoreToken("token"); err != nil {
+		t.Fatal(err)
+	}
+	return server.URL
+}
+
+// stubHook writes a hook that logs each payload as a line and then runs body
+func stubHook(t *testing.T, body string) (string, func() []hooks.Payload) {
+	t.Helper()
+	directory := t.TempDir()
+	log := filepath.Join(directory, "payloads")
+	executable := filepath.Join(directory, "hook")
+	script := "#!/bin/sh\ncat >> '" + log + "'\necho >> '" + log + "'\n" + body + "\n"
+	if err := os.WriteFile(executable, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	payloads := func() []hooks.Payload {
+		file, err := os.Open(log)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		var payloads []hooks.Payload
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var payload hooks.Payload
+			if err := json.Unmarshal(scanner.Bytes(), &payload); err != nil {
+				t.Fatalf("hook got invalid JSON %q: %v", scanner.Text(), err)
+			}
+			payloads = append(payloads, payload)
+		}
+		return payloads
+	}
+	return executable, payloads
+}
+
+func TestPassphraseChangeApply(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub hook is a shell script")
+	}
+	// Keep the hook output out of the test output
+	stderr := os.Stderr
+	os.Stderr, _ = os.Open(os.DevNull)
+	t.Cleanup(func() { os.Stderr = stderr })
+
+	account := schemas.Account{ID: "a1b2c3d4", Platform: "GitHub", Identifier: "me"}
+
+	tests := []struct {
+		name    string
+		hook    string
+		status  int
+		timeout time.Duration
+		fails   bool
+		actions []string // Of the hook payloads, in order
+		updates int      // Vault update requests
+		kept    []string // Passphrases left in the history, newest first
+	}{
+		{
+			name:    "hook and vault succeed",
+			hook:    "exit 0",
+			status:  http.StatusOK,
+			actions: []string{hooks.ActionRotate},
+			updates: 1,
+			kept:    []string{"old"},
+		},
+		{
+			name:    "hook fails, vault untouched",
+			hook:    "exit 3",
+			status:  http.StatusOK,
+			fails:   true,
+			actions: []string{hooks.ActionRotate},
+		},
+		{
+			name:    "vault fails, hook reverts",
+			hook:    "exit 0",
+			status:  http.StatusInternalServerError,
+			fails:   true,
+			actions: []string{hooks.ActionRotate, hooks.ActionRevert},
+			updates: 1,
+		},
+		{
+			name:    "hook times out, revert times out too",
+			hook:    "exec sleep 10",
+			status:  http.StatusOK,
+			timeout: 200 * time.Millisecond,
+			fails:   true,
+			actions: []string{hooks.ActionRotate, hooks.ActionRevert},
+			kept:    []string{"new"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vault := &fakeVault{status: test.status}
+			server := setupRotation(t, vault)
+			executable, payloads := stubHook(t, test.hook)
+
+			timeout := test.timeout
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			change := passphraseChange{timeout: timeout, server: server, account: account, hook: executable}
+			err := change.apply(hooks.ActionRotate, "old", "new")
+			if test.fails != (err != nil) {
+				t.Fatalf("apply() = %v, want failure %t", err, test.fails)
+			}
+
+			got := payloads()
+			if len(got) != len(test.actions) {
+				t.Fatalf("hook ran %d times, want %d", len(got), len(test.actions))
+			}
+			for index, payload := range got {
+				if payload.Action != test.actions[index] {
+					t.Errorf("hook run %d has action %q, want %q", index, payload.Action, test.actions[index])
+				}
+				old, replacement := "old", "new"
+				if payload.Action == hooks.ActionRevert {
+					old, replacement = replacement, old
+				}
+				if payload.OldPassphrase != old || payload.NewPassphrase != replacement {
+					t.Errorf("hook run %d changes %q to %q, want %q to %q",
+						index, payload.OldPassphrase, payload.NewPassphrase, old, replacement)
+				}
+				if payload.Server != server || payload.Account.ID != account.ID {
+					t.Errorf("hook run %d is for %s on %s", index, payload.Account.ID, payload.Server)
+				}
+			}
+
+			if len(vault.updates) != test.updates {
+				t.Errorf("vault got %d updates, want %d", len(vault.updates), test.updates)
+			}
+			for _, update := range vault.updates {
+				if update != "new" {
+					t.Errorf("vault was updated to %q", update)
+				}
+			}
+
+			entries, err := history.List(server, account.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var kept []string
+			for _, entry := range entries {
+				kept = append(kept, entry.Passphrase)
+			}
+			if len(kept) != len(test.kept) || (len(kept) > 0 && kept[0] != test.kept[0]) {
+				t.Errorf("history has %q, want %q", kept, test.kept)
+			}
+		})
+	}
+}
+
+func TestHookExecutable(t *testing.T) {
+	directory := t.TempDir()
+	t.Chdir(directory)
+	if err := os.WriteFile("local-hook", nil, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"./hooks/rotate.sh": filepath.Join(directory, "hooks", "rotate.sh"),
+		"local-hook":        filepath.Join(directory, "local-hook"),
+		"missing-hook":      "missing-hook",
+	}
+	if runtime.GOOS != "windows" {
+		tests["/usr/local/bin/rotate"] = "/usr/local/bin/rotate"
+	}
+	for executable, want := range tests {
+		got, err := hookExecutable(executable)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("hookExecutable(%q) = %q, want %q", executable, got, want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"os"
+	"passenger-go-cli/internal/config"
+	"passenger-go-cli/internal/passwordrules"
+	"passenger-go-cli/internal/resolver"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/utilities"
+	"path"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+func RulesCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "rules",
+		Aliases: []string{"password-rules"},
+		Usage:   "Manage site password rules used when generating passphrases for accounts.",
+		Subcommands: []*cli.Command{
+			{
+				Name:    "list",
+				Aliases: []string{"ls", "show"},
+				Usage:   "Show the configured password rules.",
+				Action: func(context *cli.Context) error {
+					configuration, err := config.LoadConfig()
+					if err != nil {
+						return cli.Exit("Error loading config: "+err.Error(), 1)
+					}
+					if len(configuration.PasswordRules) == 0 {
+						os.Stdout.WriteString("No password rules configured. Use 'rules set <pattern> <rules>' to add some.\n")
+						return nil
+					}
+
+					rows := [][]string{}
+					for _, rule := range configuration.PasswordRules {
+						rows = append(rows, []string{rule.Pattern, rule.Rules})
+					}
+					utilities.PrintTable(rows, []string{"Pattern", "Rules"})
+					return nil
+				},
+			},
+			{
+				Name:      "set",
+				Aliases:   []string{"add"},
+				Usage:     "Attach rules like 'minlength: 12; maxlength: 20; required: lower; allowed: [-_]' to a platform or URL host pattern, e.g. 'github' or '*.example.com'.",
+				ArgsUsage: "<pattern> <rules>",
+				Action: func(context *cli.Context) error {
+					if context.NArg() != 2 {
+						return cli.Exit("Usage: rules set <pattern> <rules>", 1)
+					}
+					pattern := strings.ToLower(strings.TrimSpace(context.Args().Get(0)))
+					if _, err := path.Match(pattern, ""); err != nil {
+						return cli.Exit("Invalid pattern: "+err.Error(), 1)
+					}
+					rules, err := passwordrules.Parse(context.Args().Get(1))
+					if err != nil {
+						return cli.Exit("Invalid password rules: "+err.Error(), 1)
+					}
+
+					configuration, err := config.LoadConfig()
+					if err != nil {
+						return cli.Exit("Error loading config: "+err.Error(), 1)
+					}
+					entry := config.PasswordRule{Pattern: pattern, Rules: context.Args().Get(1)}
+					replaced := false
+					for index, existing := range configuration.PasswordRules {
+						if existing.Pattern == pattern {
+							configuration.PasswordRules[index] = entry
+							replaced = true
+						}
+					}
+					if !replaced {
+						configuration.PasswordRules = append(configuration.PasswordRules, entry)
+					}
+					if err := config.SaveConfig(configuration); err != nil {
+						return cli.Exit("Error saving config: "+err.Error(), 1)
+					}
+
+					os.Stdout.WriteString("✅ Password rules for " + pattern + " set to: " + rules.String() + "\n")
+					return nil
+				},
+			},
+			{
+				Name:      "remove",
+				Aliases:   []string{"rm", "delete"},
+				Usage:     "Remove the rules of a pattern.",
+				ArgsUsage: "<pattern>",
+				Action: func(context *cli.Context) error {
+					pattern := strings.ToLower(strings.TrimSpace(context.Args().First()))
+					configuration, err := config.LoadConfig()
+					if err != nil {
+						return cli.Exit("Error loading config: "+err.Error(), 1)
+					}
+
+					kept := configuration.PasswordRules[:0]
+					for _, rule := range configuration.PasswordRules {
+						if rule.Pattern != pattern {
+							kept = append(kept, rule)
+						}
+					}
+					if len(kept) == len(configuration.PasswordRules) {
+						return cli.Exit("No password rules for "+pattern, 1)
+					}
+					configuration.PasswordRules = kept
+					if err := config.SaveConfig(configuration); err != nil {
+						return cli.Exit("Error saving config: "+err.Error(), 1)
+					}
+
+					os.Stdout.WriteString("✅ Password rules for " + pattern + " removed\n")
+					return nil
+				},
+			},
+		},
+		Action: func(context *cli.Context) error {
+			return cli.Exit("Please specify 'rules list', 'rules set <pattern> <rules>' or 'rules remove <pattern>'.", 1)
+		},
+	}
+}
+
+// rulesForAccount returns the first configured rules whose pattern matches
+// the platform or URL host of account, nil when none does
+func rulesForAccount(account schemas.Account) (*passwordrules.Rules, string, error) {
+	configuration, err := config.LoadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, entry := range configuration.PasswordRules {
+		if !accountMatches(entry.Pattern, account) {
+			continue
+		}
+		rules, err := passwordrules.Parse(entry.Rules)
+		if err != nil {
+			return nil, "", cli.Exit("Invalid password rules for "+entry.Pattern+" in config: "+err.Error(), 1)
+		}
+		return rules, entry.Pattern, nil
+	}
+	return nil, "", nil
+}
+
+// accountMatches reports whether a lowercase glob from the config matches the
+// platform or URL host of account
+func accountMatches(pattern string, account schemas.Account) bool {
+	candidates := []string{strings.ToLower(account.Platform)}
+	if host := resolver.Host(account.URL); host != "" {
+		candidates = append(candidates, host)
+	}
+	for _, candidate := range candidates {
+		if matched, _ := path.Match(pattern, candidate); matched {
+			return true
+		}
+	}
+	return false
+}
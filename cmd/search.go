@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/fuzzy"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/utilities"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+func SearchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "search",
+		Aliases:   []string{"find", "query"},
+		Usage:     "Fuzzy search accounts by platform, identifier, URL and notes",
+		ArgsUsage: "<query>",
+		Flags: []cli.Flag{
+			formatFlag(),
+			whereFlag(),
+			&cli.IntFlag{
+				Name:    "limit",
+				Aliases: []string{"n"},
+				Usage:   "Show at most this many results. Default is all.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			query := strings.Join(c.Args().Slice(), " ")
+			if strings.TrimSpace(query) == "" {
+				return cli.Exit("Search query is required, e.g. `passenger-go search github`", 1)
+			}
+
+			accounts, err := api.GetAccounts()
+			if err != nil {
+				return err
+			}
+			accounts, err = filterAccounts(c, accounts)
+			if err != nil {
+				return err
+			}
+
+			matches := fuzzy.RankAccounts(query, accounts)
+			if limit := c.Int("limit"); limit > 0 && len(matches) > limit {
+				matches = matches[:limit]
+			}
+
+			if c.IsSet("format") {
+				results := make([]schemas.Account, len(matches))
+				for index, match := range matches {
+					results[index] = match.Account
+				}
+				return utilities.PrintTemplate(os.Stdout, c.String("format"), results...)
+			}
+
+			// Like list, finding nothing is not an error
+			if len(matches) == 0 {
+				os.Stderr.WriteString("No accounts match \"" + query + "\"\n")
+				return nil
+			}
+
+			highlight := func(text string, positions []int) string {
+				if !utilities.UseColor(os.Stdout) {
+					return text
+				}
+				return fuzzy.Highlight(text, positions, "\033[1;33m", "\033[0m")
+			}
+
+			var rows [][]string
+			for _, match := range matches {
+				// Notes may span lines, keep the table on one line per account
+				notes := strings.ReplaceAll(match.Account.Notes, "\n", " ")
+				rows = append(rows, []string{
+					match.Account.ID,
+					highlight(match.Account.Platform, match.Platform),
+					highlight(match.Account.Identifier, match.Identifier),
+					highlight(match.Account.URL, match.URL),
+					highlight(notes, match.Notes),
+				})
+			}
+
+			utilities.PrintTable(rows, []string{"ID", "Platform", "Identifier", "URL", "Notes"})
+			return nil
+		},
+	}
+}
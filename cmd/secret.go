@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"passenger-go-cli/internal/config"
+	"passenger-go-cli/internal/utilities"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// revealFlag is shared by commands that write secrets, see secretOutput
+func revealFlag() *cli.IntFlag {
+	return &cli.IntFlag{
+		Name: "reveal",
+		Usage: "Show the secret on the alternate screen for this many seconds, so it stays out of the scrollback. " +
+			"reveal_seconds in the config makes this the default on a terminal.",
+	}
+}
+
+// newlineFlag is shared by commands that write secrets, see secretOutput
+func newlineFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "newline",
+		Usage: "End piped output with a line break, it is written without one by default.",
+	}
+}
+
+// secretOutput reads --copy, --reveal and --newline and checks right away that
+// a secret named label can be written, before anything is changed
+func secretOutput(c *cli.Context, label string) (utilities.SecretOutput, error) {
+	if c.Bool("copy") && c.IsSet("reveal") {
+		return utilities.SecretOutput{}, cli.Exit("--reveal cannot be combined with --copy", 1)
+	}
+	reveal, err := revealSeconds(c)
+	if err != nil {
+		return utilities.SecretOutput{}, err
+	}
+
+	output := utilities.SecretOutput{
+		Reveal:  time.Duration(reveal) * time.Second,
+		Newline: c.Bool("newline"),
+	}
+	if c.Bool("copy") {
+		output.Copy = func(label, secret string) error { return copySecret(secret, label) }
+	}
+	if err := output.Check(label); err != nil {
+		return utilities.SecretOutput{}, cli.Exit("❌ Refusing to output "+label+": "+err.Error(), 1)
+	}
+	return output, nil
+}
+
+// revealSeconds returns --reveal, or reveal_seconds from the config when the
+// secret would otherwise be printed to a terminal. 0 means print it.
+func revealSeconds(c *cli.Context) (int, error) {
+	if c.IsSet("reveal") {
+		if c.Int("reveal") <= 0 {
+			return 0, cli.Exit("--reveal must be a positive number of seconds", 1)
+		}
+		return c.Int("reveal"), nil
+	}
+	if c.Bool("copy") || !utilities.IsTerminal(os.Stdin) || !utilities.IsTerminal(os.Stdout) {
+		return 0, nil
+	}
+
+	configuration, err := config.LoadConfig()
+	if err != nil {
+		return 0, cli.Exit("Error loading config: "+err.Error(), 1)
+	}
+	return max(configuration.RevealSeconds, 0), nil
+}
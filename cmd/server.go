@@ -40,7 +40,15 @@ func ServerCommand() *cli.Command {
 					if err != nil {
 						return err
 					}
-					config.SaveConfig(&config.Config{ServerURL: serverURL})
+					configuration, err := config.LoadConfig()
+					if err != nil {
+						return cli.Exit("Error loading config: "+err.Error(), 1)
+					}
+					configuration.ServerURL = serverURL
+					err = config.SaveConfig(configuration)
+					if err != nil {
+						return cli.Exit("Error saving config: "+err.Error(), 1)
+					}
 					os.Stdout.WriteString("✅ Server URL set to " + serverURL + "\n")
 					return nil
 				},
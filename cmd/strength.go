@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"passenger-go-cli/internal/strength"
+	"passenger-go-cli/internal/utilities"
+	"strconv"
+
+	"github.com/urfave/cli/v2"
+)
+
+// weakStrength is the score below which a passphrase counts as weak
+const weakStrength = 40
+
+func allowWeakFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "allow-weak",
+		Usage: "Accept a passphrase the local strength estimate considers weak.",
+	}
+}
+
+// checkMasterPassphrase reports the estimated strength of a new master
+// passphrase and refuses weak ones unless confirmed or allowed by flag
+func checkMasterPassphrase(c *cli.Context, passphrase string) error {
+	result := strength.Check(passphrase)
+	printStrength(result)
+
+	if result.Score >= weakStrength || c.Bool("allow-weak") {
+		return nil
+	}
+	if !utilities.IsTerminal(os.Stdin) {
+		return cli.Exit("Refusing a weak master passphrase, use --allow-weak to accept it", 1)
+	}
+	confirmed, err := utilities.Confirm("Use this weak passphrase anyway?")
+	if err != nil || !confirmed {
+		return cli.Exit("Master passphrase not changed", 1)
+	}
+	return nil
+}
+
+// printStrength writes the estimate and its feedback to stderr
+func printStrength(result strength.Result) {
+	os.Stderr.WriteString("Strength: " + strconv.Itoa(result.Score) + " (" + utilities.StrengthLabel(result.Score) + "), " +
+		strength.DisplayTime(result.CrackTime) + " to crack offline\n")
+	if result.Warning != "" {
+		os.Stderr.WriteString("⚠️  " + result.Warning + "\n")
+	}
+	for _, suggestion := range result.Suggestions {
+		os.Stderr.WriteString("   - " + suggestion + "\n")
+	}
+}
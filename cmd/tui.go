@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/tui"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TUICommand() *cli.Command {
+	return &cli.Command{
+		Name:    "tui",
+		Aliases: []string{"ui", "browse", "interactive"},
+		Usage:   "Browse and manage the vault in a full-screen terminal interface",
+		Action: func(context *cli.Context) error {
+			err := tui.Run(tui.Handlers{
+				Edit: func(account schemas.Account) error {
+					return updateAccountInteractively(account)
+				},
+				Create: createAccountInteractively,
+				Copy:   copyAndClear,
+				Deleted: func(account schemas.Account) error {
+					return removePassphraseAges(account.ID)
+				},
+			})
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
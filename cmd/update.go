@@ -3,82 +3,119 @@ package cmd
 import (
 	"os"
 	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/metadata"
 	"passenger-go-cli/internal/schemas"
-	"passenger-go-cli/internal/utilities"
 
 	"github.com/urfave/cli/v2"
 )
 
 func UpdateCommand() *cli.Command {
 	return &cli.Command{
-		Name:    "update",
-		Aliases: []string{"edit", "modify", "change"},
-		Usage:   "Update an existing account with interactive form",
-		Flags: []cli.Flag{
+		Name:      "update",
+		Aliases:   []string{"modify", "change"},
+		Usage:     "Update an existing account with flags, JSON or an interactive form",
+		ArgsUsage: "<account>",
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
-				Name:     "id",
-				Aliases:  []string{"i"},
-				Usage:    "Account ID to update",
-				Required: true,
+				Name:    "id",
+				Aliases: []string{"i"},
+				Usage:   "Account to update, same as giving it as an argument",
 			},
-		},
+		}, accountInputFlags()...),
 		Action: func(context *cli.Context) error {
-			accountID := context.String("id")
+			reference := context.String("id")
+			if reference == "" {
+				reference = context.Args().First()
+			}
 
-			// Get the existing account
-			existingAccount, err := api.GetAccount(accountID)
+			input, err := readAccountInput(context)
 			if err != nil {
-				return cli.Exit("Failed to get account: "+err.Error(), 1)
+				return err
 			}
 
-			// Get the current passphrase
-			currentPassphrase, err := api.GetAccountPassphrase(accountID)
+			// Get the existing account
+			existingAccount, err := resolveAccount(reference)
 			if err != nil {
-				return cli.Exit("Failed to get account passphrase: "+err.Error(), 1)
+				return err
 			}
 
-			form := utilities.NewInteractiveForm()
+			return updateAccount(*existingAccount, input)
+		},
+	}
+}
 
-			form.AddFieldWithDefault("platform", "Platform", existingAccount.Platform, false, true)
-			form.AddFieldWithDefault("identifier", "Identifier", existingAccount.Identifier, false, true)
-			form.AddFieldWithDefault("url", "URL", existingAccount.URL, false, false)
-			form.AddFieldWithDefault("notes", "Notes", existingAccount.Notes, false, false)
-			form.AddFieldWithDefault("passphrase", "Passphrase", currentPassphrase, true, true)
+// updateAccountInteractively edits an account with the form and saves the changes
+func updateAccountInteractively(existingAccount schemas.Account) error {
+	return updateAccount(existingAccount, newAccountInput())
+}
 
-			err = form.Run()
-			if err != nil {
-				return cli.Exit("Failed to collect form data: "+err.Error(), 1)
-			}
+// updateAccount applies the given values, or opens the form when none were given
+func updateAccount(existingAccount schemas.Account, input *accountInput) error {
+	accountID := existingAccount.ID
 
-			values := form.GetValues()
+	// Get the current passphrase
+	currentPassphrase, err := api.GetAccountPassphrase(accountID)
+	if err != nil {
+		return cli.Exit("Failed to get account passphrase: "+err.Error(), 1)
+	}
 
-			// Create updated account object
-			updatedAccount := schemas.UpsertAccountRequest{
-				Platform:   values["platform"],
-				Identifier: values["identifier"],
-				URL:        values["url"],
-				Notes:      values["notes"],
-				Passphrase: values["passphrase"],
-			}
+	// Create updated account object
+	updatedAccount := input.request(schemas.UpsertAccountRequest{
+		Platform:   existingAccount.Platform,
+		Identifier: existingAccount.Identifier,
+		URL:        existingAccount.URL,
+		Notes:      existingAccount.Notes,
+		Passphrase: currentPassphrase,
+	})
 
-			// Update the account
-			err = api.UpdateAccount(accountID, updatedAccount)
-			if err != nil {
-				return cli.Exit("Failed to update account: "+err.Error(), 1)
-			}
+	if len(input.provided) == 0 {
+		values, err := completeWithForm(accountFields, map[string]string{
+			"platform":   updatedAccount.Platform,
+			"identifier": updatedAccount.Identifier,
+			"url":        updatedAccount.URL,
+			"notes":      updatedAccount.Notes,
+			"passphrase": updatedAccount.Passphrase,
+		})
+		if err != nil {
+			return err
+		}
+		for key, value := range values {
+			input.set(key, value)
+		}
+		updatedAccount = input.request(updatedAccount)
+	}
 
-			// Check if passphrase was changed
-			newPassphrase := values["passphrase"]
-			if newPassphrase != currentPassphrase {
-				// Update the passphrase
-				err = api.UpdateAccountPassphrase(accountID, newPassphrase)
-				if err != nil {
-					return cli.Exit("Failed to update passphrase: "+err.Error(), 1)
-				}
-				os.Stdout.WriteString("Passphrase updated successfully\n")
-			}
+	for _, field := range accountFields {
+		if field.isRequired && input.provided[field.key] && input.values[field.key] == "" {
+			return cli.Exit(field.label+" cannot be empty", 1)
+		}
+	}
 
-			return nil
-		},
+	// The policy is about passphrases, other changes are saved as they are
+	if updatedAccount.Passphrase != currentPassphrase {
+		err = enforcePolicy("update", input.overrideReason, policyRequest(accountID, updatedAccount), updatedAccount.Passphrase)
+		if err != nil {
+			return err
+		}
 	}
+
+	// Update the account
+	err = api.UpdateAccount(accountID, updatedAccount)
+	if err != nil {
+		return cli.Exit("Failed to update account: "+err.Error(), 1)
+	}
+
+	// Check if passphrase was changed
+	newPassphrase := updatedAccount.Passphrase
+	if newPassphrase != currentPassphrase {
+		// Update the passphrase
+		err = api.UpdateAccountPassphrase(accountID, newPassphrase)
+		if err != nil {
+			return cli.Exit("Failed to update passphrase: "+err.Error(), 1)
+		}
+		recordPassphraseChange(accountID, metadata.SourceUpdated)
+		os.Stdout.WriteString("Passphrase updated successfully\n")
+	}
+
+	return nil
 }
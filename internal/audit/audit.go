@@ -0,0 +1,262 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"strings"
+
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/strength"
+)
+
+/*
+ * Finds accounts that need attention: weak, reused and similar passphrases,
+ * missing URLs and duplicated platform/identifier pairs.
+ *
+ * Reuse is found by grouping salted HMACs, the salt is random per audit and
+ * never leaves memory, so no map is keyed by a passphrase. Similarity needs
+ * the plain passphrases, which are compared pairwise in memory by edit
+ * distance and never stored in a finding.
+ */
+
+// Severity orders findings, higher is more urgent
+type Severity int
+
+const (
+	Low Severity = iota + 1
+	Medium
+	High
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case High:
+		return "high"
+	case Medium:
+		return "medium"
+	case Low:
+		return "low"
+	}
+	return "none"
+}
+
+// ParseSeverity reads "high", "medium", "low" or "none", none is zero
+func ParseSeverity(text string) (Severity, error) {
+	switch strings.ToLower(text) {
+	case "high":
+		return High, nil
+	case "medium":
+		return Medium, nil
+	case "low":
+		return Low, nil
+	case "none":
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unknown severity %q, expected high, medium, low or none", text)
+}
+
+// Checks, in the order they are reported within a severity
+const (
+	CheckReused     = "reused"
+	CheckWeak       = "weak"
+	CheckSimilar    = "similar"
+	CheckDuplicate  = "duplicate"
+	CheckMissingURL = "missing-url"
+)
+
+var checkOrder = []string{CheckReused, CheckWeak, CheckSimilar, CheckDuplicate, CheckMissingURL}
+
+// Finding is a problem with one or more accounts
+type Finding struct {
+	Severity Severity
+	Check    string
+	Message  string
+	Accounts []schemas.Account
+}
+
+// Options tune the checks
+type Options struct {
+	MinStrength     int // Scores below this are weak
+	SimilarDistance int // Largest edit distance for similar passphrases
+}
+
+// Run audits accounts, passphrases maps account IDs to passphrases and may
+// miss some, those are only checked on what the server reports
+func Run(accounts []schemas.Account, passphrases map[string]string, options Options) ([]Finding, error) {
+	var findings []Finding
+
+	reused, err := reusedPassphrases(accounts, passphrases)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, reused...)
+	findings = append(findings, weakPassphrases(accounts, passphrases, options.MinStrength)...)
+	findings = append(findings, similarPassphrases(accounts, passphrases, options.SimilarDistance)...)
+	findings = append(findings, duplicateAccounts(accounts)...)
+	findings = append(findings, missingURLs(accounts)...)
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		if a.Severity != b.Severity {
+			return int(b.Severity - a.Severity)
+		}
+		return slices.Index(checkOrder, a.Check) - slices.Index(checkOrder, b.Check)
+	})
+	return findings, nil
+}
+
+func reusedPassphrases(accounts []schemas.Account, passphrases map[string]string) ([]Finding, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	groups := map[string][]schemas.Account{}
+	var order []string
+	for _, account := range accounts {
+		passphrase, ok := passphrases[account.ID]
+		if !ok || passphrase == "" {
+			continue
+		}
+		mac := hmac.New(sha256.New, salt)
+		mac.Write([]byte(passphrase))
+		digest := string(mac.Sum(nil))
+
+		if _, seen := groups[digest]; !seen {
+			order = append(order, digest)
+		}
+		groups[digest] = append(groups[digest], account)
+	}
+
+	var findings []Finding
+	for _, digest := range order {
+		if group := groups[digest]; len(group) > 1 {
+			findings = append(findings, Finding{
+				Severity: High,
+				Check:    CheckReused,
+				Message:  fmt.Sprintf("The same passphrase is used by %d accounts", len(group)),
+				Accounts: group,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// weakPassphrases uses the lower of the server score and the local estimate,
+// the local one also knows the platform and identifier of the account
+func weakPassphrases(accounts []schemas.Account, passphrases map[string]string, minStrength int) []Finding {
+	var findings []Finding
+	for _, account := range accounts {
+		score := account.Strength
+		message := fmt.Sprintf("Strength %d is below %d", score, minStrength)
+
+		if passphrase, ok := passphrases[account.ID]; ok {
+			result := strength.Check(passphrase, account.Platform, account.Identifier)
+			if result.Score < score {
+				score = result.Score
+				message = fmt.Sprintf("Estimated strength %d is below %d", score, minStrength)
+			}
+			if result.Warning != "" {
+				message += ": " + result.Warning
+			}
+		}
+
+		if score < minStrength {
+			findings = append(findings, Finding{
+				Severity: High,
+				Check:    CheckWeak,
+				Message:  message,
+				Accounts: []schemas.Account{account},
+			})
+		}
+	}
+	return findings
+}
+
+func similarPassphrases(accounts []schemas.Account, passphrases map[string]string, maxDistance int) []Finding {
+	if maxDistance <= 0 {
+		return nil
+	}
+
+	var findings []Finding
+	for i := range accounts {
+		first, ok := passphrases[accounts[i].ID]
+		if !ok {
+			continue
+		}
+		for j := i + 1; j < len(accounts); j++ {
+			second, ok := passphrases[accounts[j].ID]
+			if !ok || first == second {
+				continue
+			}
+
+			a, b := []rune(first), []rune(second)
+			// Cheap length check first, the distance is at least the difference
+			if abs(len(a)-len(b)) > maxDistance {
+				continue
+			}
+			distance := levenshtein(a, b)
+			// Short passphrases are all close to each other, require most characters to match
+			if distance > maxDistance || distance*2 >= min(len(a), len(b)) {
+				continue
+			}
+
+			findings = append(findings, Finding{
+				Severity: Medium,
+				Check:    CheckSimilar,
+				Message:  fmt.Sprintf("Passphrases differ in only %d characters", distance),
+				Accounts: []schemas.Account{accounts[i], accounts[j]},
+			})
+		}
+	}
+	return findings
+}
+
+func duplicateAccounts(accounts []schemas.Account) []Finding {
+	groups := map[string][]schemas.Account{}
+	var order []string
+	for _, account := range accounts {
+		key := strings.ToLower(strings.TrimSpace(account.Platform)) + "\x00" + strings.ToLower(strings.TrimSpace(account.Identifier))
+		if _, seen := groups[key]; !seen {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], account)
+	}
+
+	var findings []Finding
+	for _, key := range order {
+		if group := groups[key]; len(group) > 1 {
+			findings = append(findings, Finding{
+				Severity: Medium,
+				Check:    CheckDuplicate,
+				Message:  fmt.Sprintf("%d accounts share the platform and identifier", len(group)),
+				Accounts: group,
+			})
+		}
+	}
+	return findings
+}
+
+func missingURLs(accounts []schemas.Account) []Finding {
+	var findings []Finding
+	for _, account := range accounts {
+		if strings.TrimSpace(account.URL) == "" {
+			findings = append(findings, Finding{
+				Severity: Low,
+				Check:    CheckMissingURL,
+				Message:  "No URL, the account cannot be matched to a site",
+				Accounts: []schemas.Account{account},
+			})
+		}
+	}
+	return findings
+}
+
+func abs(number int) int {
+	if number < 0 {
+		return -number
+	}
+	return number
+}
@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"sync"
+
+	"passenger-go-cli/internal/schemas"
+)
+
+// FetchError records an account whose passphrase could not be fetched
+type FetchError struct {
+	Account schemas.Account
+	Err     error
+}
+
+// FetchPassphrases gets the passphrases of accounts with at most workers
+// requests in flight. progress, if not nil, is called after every account.
+func FetchPassphrases(
+	accounts []schemas.Account,
+	workers int,
+	fetch func(accountID string) (string, error),
+	progress func(done, total int),
+) (map[string]string, []FetchError) {
+	return Fetch(accounts, workers, fetch, progress)
+}
+
+// Fetch runs fetch for every account like FetchPassphrases, keeping whatever
+// it returns by account ID
+func Fetch[T any](
+	accounts []schemas.Account,
+	workers int,
+	fetch func(accountID string) (T, error),
+	progress func(done, total int),
+) (map[string]T, []FetchError) {
+	workers = max(1, min(workers, len(accounts)))
+
+	jobs := make(chan schemas.Account)
+	var mutex sync.Mutex
+	var group sync.WaitGroup
+	results := make(map[string]T, len(accounts))
+	var failures []FetchError
+	done := 0
+
+	for range workers {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for account := range jobs {
+				result, err := fetch(account.ID)
+
+				mutex.Lock()
+				if err != nil {
+					failures = append(failures, FetchError{Account: account, Err: err})
+				} else {
+					results[account.ID] = result
+				}
+				done++
+				if progress != nil {
+					progress(done, len(accounts))
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, account := range accounts {
+		jobs <- account
+	}
+	close(jobs)
+	group.Wait()
+
+	return results, failures
+}
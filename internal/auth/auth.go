@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"fmt"
 
 	"github.com/zalando/go-keyring"
@@ -9,6 +12,7 @@ import (
 const (
 	serviceName = "passenger-go-cli"
 	tokenKey    = "jwt-token"
+	historyKey  = "history-key"
 )
 
 func StoreToken(token string) error {
@@ -26,3 +30,28 @@ func GetToken() (string, error) {
 func ClearToken() error {
 	return keyring.Delete(serviceName, tokenKey)
 }
+
+// HistoryKey returns the AES-256 key of the local passphrase history,
+// creating it on first use
+func HistoryKey() ([]byte, error) {
+	encoded, err := keyring.Get(serviceName, historyKey)
+	if errors.Is(err, keyring.ErrNotFound) {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := keyring.Set(serviceName, historyKey, base64.StdEncoding.EncodeToString(key)); err != nil {
+			return nil, fmt.Errorf("failed to store history key: %w", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve history key: %w", err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("history key in the keyring is corrupt")
+	}
+	return key, nil
+}
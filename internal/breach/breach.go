@@ -0,0 +1,44 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Checks passphrases against the Pwned Passwords dataset. Passphrases are
+ * hashed with SHA-1 locally, online only the first 5 characters of the hash
+ * are sent and the matching suffixes are compared here (k-anonymity), offline
+ * a downloaded file of sorted "HASH:COUNT" lines is searched in place.
+ */
+
+// PrefixLength is the number of hash characters sent to a range API
+const PrefixLength = 5
+
+// Checker counts how often a SHA-1 hash appears in breaches
+type Checker interface {
+	Count(hash string) (int, error)
+}
+
+// Hash returns the uppercase hex SHA-1 of passphrase, as used by the dataset
+func Hash(passphrase string) string {
+	sum := sha1.Sum([]byte(passphrase))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// parseLine splits a "HASH:COUNT" line, the count is optional
+func parseLine(line string) (string, int, error) {
+	line = strings.TrimSpace(line)
+	hash, rawCount, found := strings.Cut(line, ":")
+	if !found {
+		return strings.ToUpper(hash), 1, nil
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(rawCount))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid count in line %q", line)
+	}
+	return strings.ToUpper(hash), count, nil
+}
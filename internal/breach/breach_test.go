@@ -0,0 +1,165 @@
+package breach
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// SHA-1 of "password"
+const passwordHash = "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"
+
+func TestHash(t *testing.T) {
+	if got := Hash("password"); got != passwordHash {
+		t.Errorf("Hash(\"password\") = %s, want %s", got, passwordHash)
+	}
+}
+
+// fakeRangeAPI answers like the Pwned Passwords range API and records the requests
+type fakeRangeAPI struct {
+	mutex    sync.Mutex
+	requests []string
+	padding  []string // Add-Padding headers received
+}
+
+func (api *fakeRangeAPI) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	api.mutex.Lock()
+	api.requests = append(api.requests, request.URL.Path)
+	api.padding = append(api.padding, request.Header.Get("Add-Padding"))
+	api.mutex.Unlock()
+
+	switch request.URL.Path {
+	case "/range/5BAA6":
+		writer.Write([]byte("003D68EB55068C33ACE09247EE4C639306B:3\r\n" +
+			"1E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004\r\n" +
+			"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:0\r\n" +
+			"\r\n"))
+	case "/range/00000":
+		writer.Write([]byte("0000000000000000000000000000000000A:0\r\n"))
+	default:
+		http.Error(writer, "unavailable", http.StatusServiceUnavailable)
+	}
+}
+
+func TestRangeChecker(t *testing.T) {
+	api := &fakeRangeAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	checker := NewRangeChecker(server.URL + "/")
+
+	tests := []struct {
+		hash string
+		want int
+	}{
+		{passwordHash, 10434004},
+		{strings.ToLower(passwordHash), 10434004},
+		{"5BAA6003D68EB55068C33ACE09247EE4C639306B", 3},
+		{"5BAA60000000000000000000000000000000000A", 0}, // Not in the range
+		{"5BAA6FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", 0}, // Padding
+		{"000000000000000000000000000000000000000A", 0}, // Padding
+	}
+	for _, test := range tests {
+		got, err := checker.Count(test.hash)
+		if err != nil {
+			t.Fatalf("Count(%s) failed: %v", test.hash, err)
+		}
+		if got != test.want {
+			t.Errorf("Count(%s) = %d, want %d", test.hash, got, test.want)
+		}
+	}
+
+	// Only the prefix leaves the machine, once per prefix
+	want := []string{"/range/5BAA6", "/range/00000"}
+	if strings.Join(api.requests, " ") != strings.Join(want, " ") {
+		t.Errorf("requested %q, want %q", api.requests, want)
+	}
+	for _, padding := range api.padding {
+		if padding != "true" {
+			t.Errorf("Add-Padding header is %q, want true", padding)
+		}
+	}
+}
+
+func TestRangeCheckerErrors(t *testing.T) {
+	server := httptest.NewServer(&fakeRangeAPI{})
+	defer server.Close()
+	checker := NewRangeChecker(server.URL)
+
+	if _, err := checker.Count("5BAA6"); err == nil || !strings.Contains(err.Error(), "invalid SHA-1") {
+		t.Errorf("Count of a short hash = %v, want an invalid hash error", err)
+	}
+	if _, err := checker.Count("FFFFF" + passwordHash[PrefixLength:]); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Count with a failing API = %v, want the status in the error", err)
+	}
+}
+
+func TestFileChecker(t *testing.T) {
+	lines := []string{
+		"0000000A1B2C3D4E5F60718293A4B5C6D7E8F901:1",
+		"1E4C9B93F3F0682250B6CF8331B7EE68FD800000:42",
+		passwordHash + ":10434004",
+		"7C4A8D09CA3762AF61E59520943DC26494F8941B:37359195",
+		"FFFFFFFF9A6C4B2D1E3F50617283940A1B2C3D4E:7",
+	}
+
+	for name, content := range map[string]string{
+		"trailing newline": strings.Join(lines, "\n") + "\n",
+		"no final newline": strings.Join(lines, "\n"),
+		"crlf":             strings.Join(lines, "\r\n") + "\r\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "hashes.txt")
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			checker, err := OpenFileChecker(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer checker.Close()
+
+			tests := []struct {
+				hash string
+				want int
+			}{
+				{"0000000A1B2C3D4E5F60718293A4B5C6D7E8F901", 1}, // First line
+				{"FFFFFFFF9A6C4B2D1E3F50617283940A1B2C3D4E", 7}, // Last line
+				{passwordHash, 10434004},                        // Middle
+				{strings.ToLower(passwordHash), 10434004},       // Lowercase input
+				{"0000000000000000000000000000000000000000", 0}, // Before the first line
+				{"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD9", 0}, // Between lines
+				{"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", 0}, // After the last line
+				{"1E4C9B93F3F0682250B6CF8331B7EE68FD8", 0},      // Prefix of a line
+			}
+			for _, test := range tests {
+				got, err := checker.Count(test.hash)
+				if err != nil {
+					t.Fatalf("Count(%s) failed: %v", test.hash, err)
+				}
+				if got != test.want {
+					t.Errorf("Count(%s) = %d, want %d", test.hash, got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileCheckerInvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.txt")
+	if err := os.WriteFile(path, []byte(passwordHash+":many\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	checker, err := OpenFileChecker(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checker.Close()
+
+	if _, err := checker.Count(passwordHash); err == nil || !strings.Contains(err.Error(), "invalid hash file") {
+		t.Errorf("Count() = %v, want an invalid hash file error", err)
+	}
+}
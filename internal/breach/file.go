@@ -0,0 +1,103 @@
+package breach
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FileChecker binary searches a file of "HASH:COUNT" lines sorted by hash,
+// like the ordered-by-hash SHA-1 download. Only the lines probed are read, so
+// the file can be far larger than memory.
+type FileChecker struct {
+	file *os.File
+	size int64
+}
+
+// OpenFileChecker opens a sorted hash file, Close releases it
+func OpenFileChecker(path string) (*FileChecker, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &FileChecker{file: file, size: info.Size()}, nil
+}
+
+func (checker *FileChecker) Close() error {
+	return checker.file.Close()
+}
+
+func (checker *FileChecker) Count(hash string) (int, error) {
+	hash = strings.ToUpper(hash)
+
+	// Candidate lines start in [low, high), low is always the start of a line
+	low, high := int64(0), checker.size
+	for low < high {
+		middle := low + (high-low)/2
+		start, err := checker.lineStart(middle)
+		if err != nil {
+			return 0, err
+		}
+		if start >= high {
+			// No line starts in [middle, high), look before it
+			high = middle
+			continue
+		}
+
+		line, next, err := checker.readLine(start)
+		if err != nil {
+			return 0, err
+		}
+		if strings.TrimSpace(line) == "" {
+			high = start
+			continue
+		}
+		lineHash, count, err := parseLine(line)
+		if err != nil {
+			return 0, fmt.Errorf("invalid hash file %s: %w", checker.file.Name(), err)
+		}
+
+		switch {
+		case lineHash == hash:
+			return count, nil
+		case hash < lineHash:
+			high = start
+		default:
+			low = next
+		}
+	}
+	return 0, nil
+}
+
+// lineStart returns the offset of the first line starting at or after offset
+func (checker *FileChecker) lineStart(offset int64) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+	reader := bufio.NewReader(io.NewSectionReader(checker.file, offset-1, checker.size-offset+1))
+	skipped, err := reader.ReadString('\n')
+	if err == io.EOF {
+		return checker.size, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return offset - 1 + int64(len(skipped)), nil
+}
+
+// readLine returns the line starting at offset and the offset after it
+func (checker *FileChecker) readLine(offset int64) (string, int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(checker.file, offset, checker.size-offset))
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", 0, err
+	}
+	return line, offset + int64(len(line)), nil
+}
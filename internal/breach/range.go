@@ -0,0 +1,100 @@
+package breach
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRangeURL is the public Pwned Passwords range API
+const DefaultRangeURL = "https://api.pwnedpasswords.com"
+
+// RangeChecker queries a range API, GET <base>/range/<prefix> answers with
+// "SUFFIX:COUNT" lines. Responses are cached per prefix.
+type RangeChecker struct {
+	baseURL string
+	client  *http.Client
+
+	mutex sync.Mutex
+	cache map[string]map[string]int
+}
+
+// NewRangeChecker creates a checker for baseURL, DefaultRangeURL when empty
+func NewRangeChecker(baseURL string) *RangeChecker {
+	if baseURL == "" {
+		baseURL = DefaultRangeURL
+	}
+	return &RangeChecker{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+		cache:   map[string]map[string]int{},
+	}
+}
+
+func (checker *RangeChecker) Count(hash string) (int, error) {
+	hash = strings.ToUpper(hash)
+	if len(hash) != 40 {
+		return 0, fmt.Errorf("invalid SHA-1 hash %q", hash)
+	}
+	prefix, suffix := hash[:PrefixLength], hash[PrefixLength:]
+
+	suffixes, err := checker.suffixes(prefix)
+	if err != nil {
+		return 0, err
+	}
+	return suffixes[suffix], nil
+}
+
+func (checker *RangeChecker) suffixes(prefix string) (map[string]int, error) {
+	checker.mutex.Lock()
+	cached, ok := checker.cache[prefix]
+	checker.mutex.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	request, err := http.NewRequest(http.MethodGet, checker.baseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Padding hides the real number of suffixes from anyone watching the response size
+	request.Header.Set("Add-Padding", "true")
+	request.Header.Set("User-Agent", "passenger-go-cli")
+
+	response, err := checker.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query range API: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("range API responded with %s", response.Status)
+	}
+
+	suffixes := map[string]int{}
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		suffix, count, err := parseLine(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		// Padding entries have a count of 0
+		if count > 0 {
+			suffixes[suffix] = count
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read range API response: %w", err)
+	}
+
+	checker.mutex.Lock()
+	checker.cache[prefix] = suffixes
+	checker.mutex.Unlock()
+	return suffixes, nil
+}
@@ -0,0 +1,111 @@
+package clipboard
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// HelperCommand is the hidden command that runs ClearLater in the helper process
+const HelperCommand = "clipboard-clear"
+
+// DefaultClearAfter is how long copied secrets stay on the clipboard
+const DefaultClearAfter = 45 * time.Second
+
+// TerminalFlag makes HelperCommand clear the terminal clipboard on its stdout
+const TerminalFlag = "terminal"
+
+// clearOSC52 sets the terminal clipboard to nothing
+const clearOSC52 = "\033]52;c;\a"
+
+// ClearAfter starts a detached helper that clears text from the clipboard
+// after a while, so it outlives this process. See Terminal for the OSC 52
+// fallback.
+func ClearAfter(text string, after time.Duration) error {
+	if Terminal() {
+		return startTerminalHelper(after)
+	}
+	return startHelper(text, after)
+}
+
+// Terminal reports whether Copy falls back to OSC 52. That clipboard cannot
+// be read back, so ClearAfter empties it whatever it holds by then, even
+// when something else was copied since.
+func Terminal() bool {
+	_, _, err := find()
+	return err != nil
+}
+
+// startHelper runs HelperCommand in the background. The text goes through a
+// pipe, not the arguments, so other users cannot read it from the process list.
+func startHelper(text string, after time.Duration) error {
+	command, err := helperCommand(after.String())
+	if err != nil {
+		return err
+	}
+	input, err := command.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := command.Start(); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(input, text); err != nil {
+		command.Process.Kill()
+		return err
+	}
+	input.Close()
+	return command.Process.Release()
+}
+
+// startTerminalHelper runs HelperCommand in the background with the terminal
+// as its stdout, a detached process cannot open /dev/tty itself
+func startTerminalHelper(after time.Duration) error {
+	terminal, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer terminal.Close()
+
+	command, err := helperCommand("--"+TerminalFlag, after.String())
+	if err != nil {
+		return err
+	}
+	command.Stdout = terminal
+	if err := command.Start(); err != nil {
+		return err
+	}
+	return command.Process.Release()
+}
+
+func helperCommand(args ...string) (*exec.Cmd, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	command := exec.Command(executable, append([]string{HelperCommand}, args...)...)
+	detach(command)
+	return command, nil
+}
+
+// ClearLater is the helper: it reads the copied text from input, waits and
+// clears the clipboard if it still holds the text
+func ClearLater(input io.Reader, after time.Duration) error {
+	text, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	time.Sleep(after)
+	_, err = ClearIf(string(text))
+	return err
+}
+
+// ClearTerminalLater is the helper for the terminal clipboard, it waits and
+// writes an empty OSC 52 sequence to terminal. Whether the terminal honoured
+// it cannot be checked.
+func ClearTerminalLater(terminal io.Writer, after time.Duration) error {
+	time.Sleep(after)
+	_, err := io.WriteString(terminal, clearOSC52)
+	return err
+}
@@ -0,0 +1,141 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoBackend is returned when no clipboard program is installed
+var ErrNoBackend = errors.New("no clipboard program found")
+
+// backend is an external program that reads the clipboard content from stdin
+type backend struct {
+	name  string
+	args  []string
+	paste []string // Program and arguments printing the clipboard content
+	clear []string // Arguments that empty the clipboard, args with empty input when nil
+}
+
+// backends returns the clipboard programs to try on this system, best first
+func backends() []backend {
+	switch runtime.GOOS {
+	case "darwin":
+		return []backend{{name: "pbcopy", paste: []string{"pbpaste"}}}
+	case "windows":
+		return []backend{{
+			name:  "clip.exe",
+			paste: []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-Command", "Get-Clipboard -Raw"},
+		}}
+	}
+
+	var candidates []backend
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append(candidates, backend{
+			name:  "wl-copy",
+			paste: []string{"wl-paste", "--no-newline"},
+			clear: []string{"--clear"},
+		})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		candidates = append(candidates,
+			backend{
+				name:  "xclip",
+				args:  []string{"-selection", "clipboard"},
+				paste: []string{"xclip", "-selection", "clipboard", "-out"},
+			},
+			backend{
+				name:  "xsel",
+				args:  []string{"--clipboard", "--input"},
+				paste: []string{"xsel", "--clipboard", "--output"},
+				clear: []string{"--clipboard", "--delete"},
+			},
+		)
+	}
+	return candidates
+}
+
+// find returns the first installed clipboard program
+func find() (backend, string, error) {
+	for _, candidate := range backends() {
+		if path, err := exec.LookPath(candidate.name); err == nil {
+			return candidate, path, nil
+		}
+	}
+	return backend{}, "", ErrNoBackend
+}
+
+// Copy puts text on the system clipboard. Without a clipboard program it
+// falls back to the OSC 52 escape sequence, which most terminals honour
+// even over SSH.
+func Copy(text string) error {
+	candidate, path, err := find()
+	if err != nil {
+		return copyOSC52(text)
+	}
+
+	command := exec.Command(path, candidate.args...)
+	command.Stdin = strings.NewReader(text)
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", candidate.name, err)
+	}
+	return nil
+}
+
+// Paste returns the clipboard content
+func Paste() (string, error) {
+	candidate, _, err := find()
+	if err != nil {
+		return "", err
+	}
+
+	output, err := exec.Command(candidate.paste[0], candidate.paste[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", candidate.paste[0], err)
+	}
+	return string(output), nil
+}
+
+// ClearIf empties the clipboard, but only while it still holds text so
+// something copied since is kept. It reports whether it cleared.
+func ClearIf(text string) (bool, error) {
+	content, err := Paste()
+	if err != nil {
+		return false, err
+	}
+	// Some paste programs add a line break
+	if strings.TrimRight(content, "\r\n") != strings.TrimRight(text, "\r\n") {
+		return false, nil
+	}
+
+	candidate, path, err := find()
+	if err != nil {
+		return false, err
+	}
+	args := candidate.clear
+	if args == nil {
+		args = candidate.args
+	}
+	command := exec.Command(path, args...)
+	command.Stdin = strings.NewReader("")
+	if err := command.Run(); err != nil {
+		return false, fmt.Errorf("%s failed: %w", candidate.name, err)
+	}
+	return true, nil
+}
+
+// copyOSC52 asks the terminal emulator to set the clipboard
+func copyOSC52(text string) error {
+	terminal, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("no clipboard program found and no terminal for OSC 52")
+	}
+	defer terminal.Close()
+
+	_, err = terminal.WriteString("\033]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a")
+	return err
+}
@@ -0,0 +1,236 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Fake clipboard programs sharing one content file, each logs how it was called
+var fakePrograms = map[string]string{
+	"wl-copy":  `if [ "$1" = --clear ]; then : > "$CONTENT"; else "$CAT" > "$CONTENT"; fi`,
+	"wl-paste": `"$CAT" "$CONTENT"`,
+	"xclip": `case "$*" in
+*-out*) "$CAT" "$CONTENT" ;;
+*) "$CAT" > "$CONTENT" ;;
+esac`,
+	"xsel": `case "$*" in
+*--output*) "$CAT" "$CONTENT" ;;
+*--delete*) : > "$CONTENT" ;;
+*) "$CAT" > "$CONTENT" ;;
+esac`,
+}
+
+type fakeClipboard struct {
+	content string // File holding the clipboard content
+	log     string // File with a line per call, like "xclip -selection clipboard"
+}
+
+// installFakes puts the named fake programs alone on PATH
+func installFakes(t *testing.T, names ...string) fakeClipboard {
+	t.Helper()
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("the fakes stand in for the Linux clipboard programs")
+	}
+	cat, err := exec.LookPath("cat")
+	if err != nil {
+		t.Skip("cat is needed by the fake clipboard programs")
+	}
+
+	directory := t.TempDir()
+	fake := fakeClipboard{
+		content: filepath.Join(directory, "content"),
+		log:     filepath.Join(directory, "log"),
+	}
+	for _, name := range names {
+		script := "#!/bin/sh\nCONTENT='" + fake.content + "'\nCAT='" + cat + "'\n" +
+			"echo \"" + name + "${*:+ $*}\" >> '" + fake.log + "'\n" +
+			fakePrograms[name] + "\n"
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(script), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", directory)
+	return fake
+}
+
+func (fake fakeClipboard) read(t *testing.T) string {
+	t.Helper()
+	content, err := os.ReadFile(fake.content)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func (fake fakeClipboard) calls(t *testing.T) []string {
+	t.Helper()
+	content, err := os.ReadFile(fake.log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+func TestBackendSelection(t *testing.T) {
+	all := []string{"wl-copy", "wl-paste", "xclip", "xsel"}
+
+	tests := []struct {
+		name      string
+		wayland   string
+		display   string
+		installed []string
+		copy      string // Call expected to copy
+		paste     string // Call expected to paste
+	}{
+		{
+			name:      "wayland first",
+			wayland:   "wayland-0",
+			display:   ":0",
+			installed: all,
+			copy:      "wl-copy",
+			paste:     "wl-paste --no-newline",
+		},
+		{
+			name:      "x11 without wayland",
+			display:   ":0",
+			installed: all,
+			copy:      "xclip -selection clipboard",
+			paste:     "xclip -selection clipboard -out",
+		},
+		{
+			name:      "x11 when wl-copy is missing",
+			wayland:   "wayland-0",
+			display:   ":0",
+			installed: []string{"xclip"},
+			copy:      "xclip -selection clipboard",
+			paste:     "xclip -selection clipboard -out",
+		},
+		{
+			name:      "xsel without xclip",
+			display:   ":0",
+			installed: []string{"xsel"},
+			copy:      "xsel --clipboard --input",
+			paste:     "xsel --clipboard --output",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := installFakes(t, test.installed...)
+			t.Setenv("WAYLAND_DISPLAY", test.wayland)
+			t.Setenv("DISPLAY", test.display)
+
+			if err := Copy("correct horse"); err != nil {
+				t.Fatalf("Copy() failed: %v", err)
+			}
+			if content := fake.read(t); content != "correct horse" {
+				t.Errorf("clipboard holds %q, want %q", content, "correct horse")
+			}
+			pasted, err := Paste()
+			if err != nil {
+				t.Fatalf("Paste() failed: %v", err)
+			}
+			if pasted != "correct horse" {
+				t.Errorf("Paste() = %q, want %q", pasted, "correct horse")
+			}
+
+			calls := fake.calls(t)
+			if len(calls) != 2 || calls[0] != test.copy || calls[1] != test.paste {
+				t.Errorf("calls = %q, want %q", calls, []string{test.copy, test.paste})
+			}
+		})
+	}
+}
+
+func TestNoBackend(t *testing.T) {
+	installFakes(t, "wl-copy", "wl-paste", "xclip", "xsel")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", "")
+
+	if _, _, err := find(); !errors.Is(err, ErrNoBackend) {
+		t.Errorf("find() = %v, want ErrNoBackend", err)
+	}
+	if !Terminal() {
+		t.Error("Terminal() = false without a display")
+	}
+}
+
+func TestClearIf(t *testing.T) {
+	for _, backend := range []struct {
+		wayland   string
+		installed []string
+	}{
+		{"wayland-0", []string{"wl-copy", "wl-paste"}},
+		{"", []string{"xclip"}},
+		{"", []string{"xsel"}},
+	} {
+		t.Run(backend.installed[0], func(t *testing.T) {
+			fake := installFakes(t, backend.installed...)
+			t.Setenv("WAYLAND_DISPLAY", backend.wayland)
+			t.Setenv("DISPLAY", ":0")
+
+			if err := Copy("secret"); err != nil {
+				t.Fatal(err)
+			}
+			cleared, err := ClearIf("secret")
+			if err != nil || !cleared {
+				t.Fatalf("ClearIf() = %t, %v, want it cleared", cleared, err)
+			}
+			if content := fake.read(t); content != "" {
+				t.Errorf("clipboard holds %q after clearing", content)
+			}
+
+			// Something copied since is kept
+			if err := Copy("secret"); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(fake.content, []byte("copied later"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cleared, err = ClearIf("secret")
+			if err != nil || cleared {
+				t.Fatalf("ClearIf() = %t, %v, want it left alone", cleared, err)
+			}
+			if content := fake.read(t); content != "copied later" {
+				t.Errorf("clipboard holds %q, want %q", content, "copied later")
+			}
+		})
+	}
+}
+
+func TestClearLater(t *testing.T) {
+	fake := installFakes(t, "xclip")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", ":0")
+
+	if err := Copy("secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClearLater(strings.NewReader("secret"), 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if content := fake.read(t); content != "" {
+		t.Errorf("clipboard holds %q after clearing", content)
+	}
+}
+
+func TestClearTerminalLater(t *testing.T) {
+	var terminal bytes.Buffer
+	start := time.Now()
+	if err := ClearTerminalLater(&terminal, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("cleared after %s, before the delay", elapsed)
+	}
+	if terminal.String() != "\033]52;c;\a" {
+		t.Errorf("wrote %q, want an empty OSC 52 sequence", terminal.String())
+	}
+}
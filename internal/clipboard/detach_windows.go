@@ -0,0 +1,17 @@
+//go:build windows
+
+package clipboard
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachedProcess is DETACHED_PROCESS, the command gets no console
+const detachedProcess = 0x00000008
+
+// detach starts the command without a console, so closing the terminal
+// does not end it
+func detach(command *exec.Cmd) {
+	command.SysProcAttr = &syscall.SysProcAttr{CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP}
+}
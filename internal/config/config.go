@@ -2,6 +2,8 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 )
@@ -14,15 +16,44 @@ import (
  */
 
 type Config struct {
-	ServerURL string `json:"server_url,omitempty"`
+	ServerURL      string         `json:"server_url,omitempty"`
+	PasswordRules  []PasswordRule `json:"password_rules,omitempty"`
+	BreachAPI      string         `json:"breach_api,omitempty"`  // Range API base URL, the public one when empty
+	BreachFile     string         `json:"breach_file,omitempty"` // Sorted SHA-1 hash file used instead of the API
+	RotationHooks  []RotationHook `json:"rotation_hooks,omitempty"`
+	ClipboardClear string         `json:"clipboard_clear,omitempty"` // How long copied secrets stay, like 45s, 0 keeps them
+	RevealSeconds  int            `json:"reveal_seconds,omitempty"`  // Makes secrets revealed on a terminal by default
 }
 
-func getConfigPath() (string, error) {
+// PasswordRule attaches rules in Apple's passwordrules syntax to accounts
+// whose platform or URL host matches Pattern, a case-insensitive glob
+type PasswordRule struct {
+	Pattern string `json:"pattern"`
+	Rules   string `json:"rules"`
+}
+
+// RotationHook runs Command when an account whose ID, platform or URL host
+// matches Pattern is rotated, see the hooks package
+type RotationHook struct {
+	Pattern string `json:"pattern"`
+	Command string `json:"command"`
+}
+
+// GetConfigDir returns the directory holding config.json and other user files
+func GetConfigDir() (string, error) {
 	dir, err := os.UserConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, "passenger-go", "config.json"), nil
+	return filepath.Join(dir, "passenger-go"), nil
+}
+
+func getConfigPath() (string, error) {
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
 }
 
 func LoadConfig() (*Config, error) {
@@ -34,12 +65,20 @@ func LoadConfig() (*Config, error) {
 	config := &Config{}
 
 	reader, err := os.Open(path)
-	if err != nil {
+	if errors.Is(err, os.ErrNotExist) {
+		// Creating it is a convenience, a read-only config directory still works
 		SaveConfig(config)
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 	defer reader.Close()
 
-	json.NewDecoder(reader).Decode(config)
+	// A broken file must not be replaced by an empty config on the next save
+	if err := json.NewDecoder(reader).Decode(config); err != nil {
+		return nil, fmt.Errorf("%s is not valid JSON: %w", path, err)
+	}
 	return config, nil
 }
 
@@ -0,0 +1,79 @@
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+
+	"passenger-go-cli/internal/schemas"
+)
+
+// Field weights, a hit on the platform matters more than a hit in the notes
+const (
+	weightPlatform   = 4
+	weightIdentifier = 3
+	weightURL        = 2
+	weightNotes      = 1
+)
+
+// AccountMatch is a ranked search result with matched rune positions per field
+type AccountMatch struct {
+	Account    schemas.Account
+	Score      int
+	Platform   []int
+	Identifier []int
+	URL        []int
+	Notes      []int
+}
+
+// RankAccounts scores accounts against a whitespace separated query. Every
+// term has to match at least one field. Results are sorted best first.
+func RankAccounts(query string, accounts []schemas.Account) []AccountMatch {
+	terms := strings.Fields(query)
+	matches := make([]AccountMatch, 0, len(accounts))
+
+	for _, account := range accounts {
+		match, ok := matchAccount(terms, account)
+		if ok {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+func matchAccount(terms []string, account schemas.Account) (AccountMatch, bool) {
+	match := AccountMatch{Account: account}
+	fields := []struct {
+		text      string
+		weight    int
+		positions *[]int
+	}{
+		{account.Platform, weightPlatform, &match.Platform},
+		{account.Identifier, weightIdentifier, &match.Identifier},
+		{account.URL, weightURL, &match.URL},
+		{account.Notes, weightNotes, &match.Notes},
+	}
+
+	for _, term := range terms {
+		matched := false
+		best := 0
+		for _, field := range fields {
+			score, positions, ok := Match(term, field.text)
+			if !ok {
+				continue
+			}
+			matched = true
+			*field.positions = append(*field.positions, positions...)
+			best = max(best, score*field.weight)
+		}
+		if !matched {
+			return match, false
+		}
+		match.Score += best
+	}
+
+	return match, true
+}
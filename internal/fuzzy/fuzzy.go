@@ -0,0 +1,169 @@
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	scoreExact       = 1000
+	scorePrefix      = 500
+	scoreSubstring   = 250
+	scoreCharacter   = 10
+	bonusConsecutive = 15
+	bonusBoundary    = 20
+	penaltyGap       = 1
+	penaltyLeading   = 1
+	maxLeadingGap    = 10
+)
+
+// Match checks whether every character of pattern appears in text in order,
+// ignoring case. It returns a score where higher is better and the rune
+// positions in text that matched, so callers can highlight them.
+func Match(pattern, text string) (int, []int, bool) {
+	patternRunes := toLowerRunes(pattern)
+	textRunes := []rune(text)
+	lowerRunes := toLowerRunes(text)
+
+	if len(patternRunes) == 0 {
+		return 0, nil, true
+	}
+	if len(patternRunes) > len(lowerRunes) {
+		return 0, nil, false
+	}
+
+	if score, positions, ok := substringMatch(patternRunes, lowerRunes); ok {
+		return score, positions, true
+	}
+
+	return subsequenceMatch(patternRunes, textRunes, lowerRunes)
+}
+
+// toLowerRunes lowercases rune by rune, unlike strings.ToLower it keeps the rune
+// count so positions line up with the original text
+func toLowerRunes(text string) []rune {
+	runes := []rune(text)
+	for index, character := range runes {
+		runes[index] = unicode.ToLower(character)
+	}
+	return runes
+}
+
+// substringMatch rewards exact, prefix and contiguous matches
+func substringMatch(pattern, text []rune) (int, []int, bool) {
+	index := strings.Index(string(text), string(pattern))
+	if index < 0 {
+		return 0, nil, false
+	}
+
+	start := len([]rune(string(text)[:index]))
+	positions := make([]int, len(pattern))
+	for offset := range pattern {
+		positions[offset] = start + offset
+	}
+
+	score := scoreSubstring
+	switch {
+	case len(pattern) == len(text):
+		score = scoreExact
+	case start == 0:
+		score = scorePrefix
+	case isBoundary(text, start):
+		score += bonusBoundary
+	}
+	score += len(pattern) * (scoreCharacter + bonusConsecutive)
+	score -= len(text) - len(pattern) // Prefer shorter haystacks
+
+	return score, positions, true
+}
+
+// subsequenceMatch greedily places pattern characters, preferring word boundaries
+func subsequenceMatch(pattern, text, lower []rune) (int, []int, bool) {
+	positions := make([]int, 0, len(pattern))
+	score := 0
+	textIndex := 0
+
+	for patternIndex, character := range pattern {
+		found := -1
+		// Look ahead for a boundary occurrence before settling for the first one
+		for candidate := textIndex; candidate < len(lower); candidate++ {
+			if lower[candidate] != character {
+				continue
+			}
+			if found < 0 {
+				found = candidate
+			}
+			if isBoundary(text, candidate) {
+				found = candidate
+				break
+			}
+			if patternIndex > 0 && candidate == positions[patternIndex-1]+1 {
+				break
+			}
+		}
+		if found < 0 {
+			return 0, nil, false
+		}
+
+		score += scoreCharacter
+		if isBoundary(text, found) {
+			score += bonusBoundary
+		}
+		if patternIndex > 0 {
+			gap := found - positions[patternIndex-1] - 1
+			if gap == 0 {
+				score += bonusConsecutive
+			} else {
+				score -= gap * penaltyGap
+			}
+		} else {
+			score -= min(found, maxLeadingGap) * penaltyLeading
+		}
+
+		positions = append(positions, found)
+		textIndex = found + 1
+	}
+
+	return score, positions, true
+}
+
+// isBoundary reports whether the rune at index starts a word
+func isBoundary(text []rune, index int) bool {
+	if index == 0 {
+		return true
+	}
+	previous, current := text[index-1], text[index]
+	if !unicode.IsLetter(previous) && !unicode.IsDigit(previous) {
+		return true
+	}
+	return unicode.IsLower(previous) && unicode.IsUpper(current)
+}
+
+// Highlight wraps the runes at positions with the given prefix and suffix
+func Highlight(text string, positions []int, prefix, suffix string) string {
+	if len(positions) == 0 {
+		return text
+	}
+
+	marked := make(map[int]bool, len(positions))
+	for _, position := range positions {
+		marked[position] = true
+	}
+
+	var builder strings.Builder
+	inside := false
+	for index, character := range []rune(text) {
+		if marked[index] && !inside {
+			builder.WriteString(prefix)
+			inside = true
+		} else if !marked[index] && inside {
+			builder.WriteString(suffix)
+			inside = false
+		}
+		builder.WriteRune(character)
+	}
+	if inside {
+		builder.WriteString(suffix)
+	}
+	return builder.String()
+}
@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sort"
+)
+
+/*
+ * Alternates characters of a passphrase with substitutes, like the server's
+ * /generate/alternative endpoint but offline. Every character with
+ * substitutes is replaced with a probability of one half, at least one
+ * always is.
+ */
+
+// SubstitutionMap lists the substitutes of each character
+type SubstitutionMap map[rune][]rune
+
+// Random returns a uniformly distributed number in [0, n)
+type Random func(n int) (int, error)
+
+// SecureRandom draws from crypto/rand
+func SecureRandom() Random {
+	return randomIndex
+}
+
+// SeededRandom is deterministic for a seed, only meant for reproducible output
+func SeededRandom(seed uint64) Random {
+	source := rand.New(rand.NewPCG(seed, seed))
+	return func(n int) (int, error) {
+		return source.IntN(n), nil
+	}
+}
+
+// Substitution maps built in, see KeyboardMap for the layout aware one
+var substitutionMaps = map[string]SubstitutionMap{
+	"leet": {
+		'a': []rune("4@"), 'A': []rune("4@"),
+		'b': []rune("8"), 'B': []rune("8"),
+		'e': []rune("3"), 'E': []rune("3"),
+		'g': []rune("9"), 'G': []rune("6"),
+		'i': []rune("1!"), 'I': []rune("1!"),
+		'l': []rune("1|"), 'L': []rune("1"),
+		'o': []rune("0"), 'O': []rune("0"),
+		's': []rune("5$"), 'S': []rune("5$"),
+		't': []rune("7+"), 'T': []rune("7+"),
+		'z': []rune("2"), 'Z': []rune("2"),
+	},
+	// Look-alikes that never produce characters easily confused when read,
+	// like l, 1, I, O and 0
+	"homoglyph-safe": {
+		'a': []rune("@4"), 'A': []rune("@4"),
+		'b': []rune("8"), 'B': []rune("8"),
+		'c': []rune("("), 'C': []rune("("),
+		'e': []rune("3"), 'E': []rune("3"),
+		'g': []rune("9"), 'G': []rune("6"),
+		'h': []rune("#"), 'H': []rune("#"),
+		'i': []rune("!"),
+		's': []rune("$5"), 'S': []rune("$5"),
+		't': []rune("+7"), 'T': []rune("+7"),
+		'x': []rune("%"), 'X': []rune("%"),
+		'z': []rune("2"), 'Z': []rune("2"),
+	},
+}
+
+// keyboardLayouts pair the unshifted and shifted characters of each key
+var keyboardLayouts = map[string][2]string{
+	"us": {"`1234567890-=[];'\\,./", "~!@#$%^&*()_+{}:\"|<>?"},
+	"uk": {"`1234567890-=[];'#,./", "¬!\"£$%^&*()_+{}:@~<>?"},
+	"de": {"^1234567890ß´+#,.-<", "°!\"§$%&/()=?`*';:_>"},
+}
+
+// SubstitutionMapNames lists the built in maps
+func SubstitutionMapNames() []string {
+	names := []string{"keyboard"}
+	for name := range substitutionMaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinSubstitutionMap returns a built in map, "keyboard" uses layout
+func BuiltinSubstitutionMap(name, layout string) (SubstitutionMap, bool, error) {
+	if name == "keyboard" {
+		substitutions, err := KeyboardMap(layout)
+		return substitutions, true, err
+	}
+	substitutions, ok := substitutionMaps[name]
+	return substitutions, ok, nil
+}
+
+// KeyboardMap swaps characters with the other character on the same key,
+// so the result is as easy to type as the original on that layout
+func KeyboardMap(layout string) (SubstitutionMap, error) {
+	keys, ok := keyboardLayouts[layout]
+	if !ok {
+		return nil, fmt.Errorf("unknown keyboard layout %q, expected us, uk or de", layout)
+	}
+
+	substitutions := SubstitutionMap{}
+	for letter := 'a'; letter <= 'z'; letter++ {
+		substitutions[letter] = []rune{letter - 'a' + 'A'}
+		substitutions[letter-'a'+'A'] = []rune{letter}
+	}
+	unshifted, shifted := []rune(keys[0]), []rune(keys[1])
+	for index := range unshifted {
+		substitutions[unshifted[index]] = []rune{shifted[index]}
+		substitutions[shifted[index]] = []rune{unshifted[index]}
+	}
+	return substitutions, nil
+}
+
+// LoadSubstitutionMap reads a JSON object of characters to substitutes,
+// like {"a": "@4", "s": "$"}
+func LoadSubstitutionMap(path string) (SubstitutionMap, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("invalid substitution map %s: %w", path, err)
+	}
+
+	substitutions := SubstitutionMap{}
+	for key, value := range raw {
+		characters := []rune(key)
+		if len(characters) != 1 || value == "" {
+			return nil, fmt.Errorf("invalid substitution map %s: %q needs to be a single character with at least one substitute", path, key)
+		}
+		substitutions[characters[0]] = []rune(value)
+	}
+	return substitutions, nil
+}
+
+// Alternate replaces characters of passphrase with their substitutes
+func Alternate(passphrase string, substitutions SubstitutionMap, random Random) (string, error) {
+	runes := []rune(passphrase)
+
+	var candidates []int
+	for index, character := range runes {
+		if len(substitutions[character]) > 0 {
+			candidates = append(candidates, index)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no character of the passphrase has a substitute")
+	}
+
+	forced, err := random(len(candidates))
+	if err != nil {
+		return "", err
+	}
+	for position, index := range candidates {
+		if position != forced {
+			coin, err := random(2)
+			if err != nil {
+				return "", err
+			}
+			if coin == 0 {
+				continue
+			}
+		}
+
+		options := substitutions[runes[index]]
+		choice, err := random(len(options))
+		if err != nil {
+			return "", err
+		}
+		runes[index] = options[choice]
+	}
+	return string(runes), nil
+}
@@ -0,0 +1,168 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAlternateSeeded(t *testing.T) {
+	custom := filepath.Join(t.TempDir(), "custom.json")
+	if err := os.WriteFile(custom, []byte(`{"a": "@", "o": "0", "é": "e3"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		layout     string // For the keyboard map, a path for custom maps
+		passphrase string
+		want       map[uint64]string // By seed
+	}{
+		{
+			name:       "leet",
+			passphrase: "correct horse battery staple",
+			want:       map[uint64]string{1: "c0rrec7 hor$e 84+tery stap13", 42: "c0rrec+ h0r$3 8a+tery s7@ple"},
+		},
+		{
+			name:       "homoglyph-safe",
+			passphrase: "correct horse battery staple",
+			want:       map[uint64]string{1: "(orre(t #ors3 84ttery st@pl3", 42: "(orre(+ #or$3 b4ttery $7aple"},
+		},
+		{
+			name:       "keyboard",
+			layout:     "us",
+			passphrase: "Hunter2-Tango;1",
+			want:       map[uint64]string{1: "huNtEr@_tango:!", 42: "huNtER@-tAngO:1"},
+		},
+		{
+			name:       "keyboard",
+			layout:     "uk",
+			passphrase: "Hunter2-Tango#1",
+			want:       map[uint64]string{1: "huNtEr\"_tango~!", 42: "huNtER\"-tAngO~1"},
+		},
+		{
+			name:       "keyboard",
+			layout:     "de",
+			passphrase: "Hunter2-Tango#1",
+			want:       map[uint64]string{1: "huNtEr\"_tango'!", 42: "huNtER\"-tAngO'1"},
+		},
+		{
+			name:       "custom",
+			layout:     custom,
+			passphrase: "café au lait avocado",
+			want:       map[uint64]string{1: "c@fé au l@it av0cad0", 42: "c@fé @u l@it @v0c@do"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+" "+filepath.Base(test.layout), func(t *testing.T) {
+			var substitutions SubstitutionMap
+			var err error
+			if test.name == "custom" {
+				substitutions, err = LoadSubstitutionMap(test.layout)
+			} else {
+				substitutions, _, err = BuiltinSubstitutionMap(test.name, test.layout)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for seed, want := range test.want {
+				got, err := Alternate(test.passphrase, substitutions, SeededRandom(seed))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("seed %d: Alternate(%q) = %q, want %q", seed, test.passphrase, got, want)
+				}
+				checkSubstitutes(t, test.passphrase, got, substitutions)
+
+				again, _ := Alternate(test.passphrase, substitutions, SeededRandom(seed))
+				if again != got {
+					t.Errorf("seed %d: Alternate is not deterministic, got %q and %q", seed, got, again)
+				}
+			}
+		})
+	}
+}
+
+// checkSubstitutes verifies that every changed character became one of its
+// substitutes and at least one changed
+func checkSubstitutes(t *testing.T, original, alternated string, substitutions SubstitutionMap) {
+	t.Helper()
+	before, after := []rune(original), []rune(alternated)
+	if len(before) != len(after) {
+		t.Fatalf("%q and %q differ in length", original, alternated)
+	}
+	changed := 0
+	for index := range before {
+		if before[index] == after[index] {
+			continue
+		}
+		changed++
+		if !slices.Contains(substitutions[before[index]], after[index]) {
+			t.Errorf("%q became %q, which is not one of its substitutes", before[index], after[index])
+		}
+	}
+	if changed == 0 {
+		t.Errorf("%q was not changed", original)
+	}
+}
+
+func TestKeyboardLayouts(t *testing.T) {
+	for layout, keys := range keyboardLayouts {
+		unshifted, shifted := []rune(keys[0]), []rune(keys[1])
+		if len(unshifted) != len(shifted) {
+			t.Errorf("layout %s has %d unshifted and %d shifted characters", layout, len(unshifted), len(shifted))
+			continue
+		}
+
+		// A character on two keys would make the swap depend on map order
+		seen := map[rune]bool{}
+		for _, character := range append(unshifted, shifted...) {
+			if seen[character] || (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z') {
+				t.Errorf("layout %s has %q twice", layout, character)
+			}
+			seen[character] = true
+		}
+
+		substitutions, err := KeyboardMap(layout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for character, substitutes := range substitutions {
+			back := substitutions[substitutes[0]]
+			if len(substitutes) != 1 || len(back) != 1 || back[0] != character {
+				t.Errorf("layout %s: %q and %q do not swap with each other", layout, character, substitutes)
+			}
+		}
+	}
+
+	if _, err := KeyboardMap("fr"); err == nil {
+		t.Error("KeyboardMap(\"fr\") succeeded, want an unknown layout error")
+	}
+}
+
+func TestAlternateErrors(t *testing.T) {
+	if _, err := Alternate("xyq", substitutionMaps["leet"], SeededRandom(1)); err == nil {
+		t.Error("Alternate without substitutable characters succeeded")
+	}
+
+	directory := t.TempDir()
+	for name, content := range map[string]string{
+		"not-json.json":   `a: @`,
+		"long-key.json":   `{"ab": "@"}`,
+		"empty-subs.json": `{"a": ""}`,
+	} {
+		path := filepath.Join(directory, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadSubstitutionMap(path)
+		if err == nil || !strings.Contains(err.Error(), "invalid substitution map") {
+			t.Errorf("LoadSubstitutionMap(%s) = %v, want an invalid map error", name, err)
+		}
+	}
+}
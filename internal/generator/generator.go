@@ -0,0 +1,187 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+/*
+ * Generates passphrases locally with crypto/rand, so no server is needed.
+ *
+ * Passphrases are built either from character classes, with an optional
+ * minimum count per class, from a custom alphabet, or from a pattern like
+ * "Cvcv-9999-Cvcv" (see pattern.go).
+ */
+
+const (
+	Lower   = "abcdefghijklmnopqrstuvwxyz"
+	Upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Digits  = "0123456789"
+	Symbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+	// Ambiguous characters are easily confused when read or typed by hand
+	Ambiguous = "Il1|O0o`'\""
+)
+
+// Options describe what a generated passphrase looks like
+type Options struct {
+	Length int
+
+	// Character classes to draw from and how many of each must be included
+	Lower, Upper, Digits, Symbols             bool
+	MinLower, MinUpper, MinDigits, MinSymbols int
+
+	// ExcludeAmbiguous removes characters like l, 1 and O, 0 from every set
+	ExcludeAmbiguous bool
+
+	// Alphabet replaces the character classes when set
+	Alphabet string
+
+	// Pattern replaces length, classes and alphabet when set
+	Pattern string
+}
+
+// DefaultOptions uses every character class, matching what the server generates
+func DefaultOptions(length int) Options {
+	return Options{
+		Length:  length,
+		Lower:   true,
+		Upper:   true,
+		Digits:  true,
+		Symbols: true,
+	}
+}
+
+// class is a character set with the minimum number of its characters
+type class struct {
+	name    string
+	set     []rune
+	minimum int
+}
+
+// Generate creates a passphrase following options
+func Generate(options Options) (string, error) {
+	if options.Pattern != "" {
+		return generatePattern(options.Pattern, options.ExcludeAmbiguous)
+	}
+
+	classes, err := options.classes()
+	if err != nil {
+		return "", err
+	}
+
+	alphabet := joinClasses(classes)
+	passphrase := make([]rune, 0, options.Length)
+
+	// Required characters first, the shuffle below hides their positions
+	for _, class := range classes {
+		for range class.minimum {
+			character, err := pick(class.set)
+			if err != nil {
+				return "", err
+			}
+			passphrase = append(passphrase, character)
+		}
+	}
+	for len(passphrase) < options.Length {
+		character, err := pick(alphabet)
+		if err != nil {
+			return "", err
+		}
+		passphrase = append(passphrase, character)
+	}
+
+	if err := shuffle(passphrase); err != nil {
+		return "", err
+	}
+	return string(passphrase), nil
+}
+
+// Entropy estimates the bits of entropy of passphrases generated with options.
+// Minimums reduce it slightly, that is ignored.
+func Entropy(options Options) (float64, error) {
+	if options.Pattern != "" {
+		return patternEntropy(options.Pattern, options.ExcludeAmbiguous)
+	}
+
+	classes, err := options.classes()
+	if err != nil {
+		return 0, err
+	}
+	return float64(options.Length) * math.Log2(float64(len(joinClasses(classes)))), nil
+}
+
+// classes validates options and returns the character sets to draw from
+func (options Options) classes() ([]class, error) {
+	if options.Length < 1 {
+		return nil, fmt.Errorf("length must be at least 1")
+	}
+
+	var classes []class
+	if options.Alphabet != "" {
+		set := uniqueRunes(options.Alphabet, options.ExcludeAmbiguous)
+		if len(set) < 2 {
+			return nil, fmt.Errorf("the alphabet needs at least two different characters")
+		}
+		classes = append(classes, class{name: "alphabet", set: set})
+	} else {
+		candidates := []struct {
+			enabled bool
+			class   class
+		}{
+			{options.Lower, class{"lower", []rune(Lower), options.MinLower}},
+			{options.Upper, class{"upper", []rune(Upper), options.MinUpper}},
+			{options.Digits, class{"digits", []rune(Digits), options.MinDigits}},
+			{options.Symbols, class{"symbols", []rune(Symbols), options.MinSymbols}},
+		}
+		for _, candidate := range candidates {
+			if !candidate.enabled {
+				if candidate.class.minimum > 0 {
+					return nil, fmt.Errorf("a minimum for %s is set but %s are excluded", candidate.class.name, candidate.class.name)
+				}
+				continue
+			}
+			candidate.class.set = uniqueRunes(string(candidate.class.set), options.ExcludeAmbiguous)
+			classes = append(classes, candidate.class)
+		}
+		if len(classes) == 0 {
+			return nil, fmt.Errorf("at least one character class is needed")
+		}
+	}
+
+	required := 0
+	for _, class := range classes {
+		if class.minimum < 0 {
+			return nil, fmt.Errorf("the minimum for %s cannot be negative", class.name)
+		}
+		required += class.minimum
+	}
+	if required > options.Length {
+		return nil, fmt.Errorf("the minimums add up to %d characters, more than the length of %d", required, options.Length)
+	}
+
+	return classes, nil
+}
+
+func joinClasses(classes []class) []rune {
+	var alphabet []rune
+	for _, class := range classes {
+		alphabet = append(alphabet, class.set...)
+	}
+	return alphabet
+}
+
+// uniqueRunes returns the distinct characters of text, optionally without ambiguous ones
+func uniqueRunes(text string, excludeAmbiguous bool) []rune {
+	seen := map[rune]bool{}
+	var set []rune
+	for _, character := range text {
+		if seen[character] || (excludeAmbiguous && strings.ContainsRune(Ambiguous, character)) {
+			continue
+		}
+		seen[character] = true
+		set = append(set, character)
+	}
+	return set
+}
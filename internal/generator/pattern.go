@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+ * Pattern placeholders, every other character is copied as is:
+ *   C / c  upper / lower case consonant
+ *   V / v  upper / lower case vowel
+ *   A / a  upper / lower case letter
+ *   9      digit
+ *   #      symbol
+ *   *      any letter, digit or symbol
+ *   \      copies the next character, e.g. \9 for a literal 9
+ */
+
+var placeholders = map[rune]string{
+	'C': "BCDFGHJKLMNPQRSTVWXYZ",
+	'c': "bcdfghjklmnpqrstvwxyz",
+	'V': "AEIOU",
+	'v': "aeiou",
+	'A': Upper,
+	'a': Lower,
+	'9': Digits,
+	'#': Symbols,
+	'*': Lower + Upper + Digits + Symbols,
+}
+
+// patternPart is a literal character or a set to pick one character from
+type patternPart struct {
+	literal rune
+	set     []rune
+}
+
+func parsePattern(pattern string, excludeAmbiguous bool) ([]patternPart, error) {
+	var parts []patternPart
+	runes := []rune(pattern)
+
+	for index := 0; index < len(runes); index++ {
+		character := runes[index]
+		if character == '\\' {
+			if index == len(runes)-1 {
+				return nil, fmt.Errorf("the pattern ends with an unfinished escape")
+			}
+			index++
+			parts = append(parts, patternPart{literal: runes[index]})
+			continue
+		}
+
+		set, ok := placeholders[character]
+		if !ok {
+			parts = append(parts, patternPart{literal: character})
+			continue
+		}
+		parts = append(parts, patternPart{set: uniqueRunes(set, excludeAmbiguous)})
+	}
+
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("the pattern is empty")
+	}
+	return parts, nil
+}
+
+func generatePattern(pattern string, excludeAmbiguous bool) (string, error) {
+	parts, err := parsePattern(pattern, excludeAmbiguous)
+	if err != nil {
+		return "", err
+	}
+
+	passphrase := make([]rune, 0, len(parts))
+	for _, part := range parts {
+		if part.set == nil {
+			passphrase = append(passphrase, part.literal)
+			continue
+		}
+		character, err := pick(part.set)
+		if err != nil {
+			return "", err
+		}
+		passphrase = append(passphrase, character)
+	}
+	return string(passphrase), nil
+}
+
+// patternEntropy adds up the entropy of every placeholder, literals add nothing
+func patternEntropy(pattern string, excludeAmbiguous bool) (float64, error) {
+	parts, err := parsePattern(pattern, excludeAmbiguous)
+	if err != nil {
+		return 0, err
+	}
+
+	bits := 0.0
+	for _, part := range parts {
+		if part.set != nil {
+			bits += math.Log2(float64(len(part.set)))
+		}
+	}
+	return bits, nil
+}
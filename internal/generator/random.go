@@ -0,0 +1,36 @@
+package generator
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// randomIndex returns a uniformly distributed number in [0, n)
+func randomIndex(n int) (int, error) {
+	value, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(value.Int64()), nil
+}
+
+// pick returns a random rune of set
+func pick(set []rune) (rune, error) {
+	index, err := randomIndex(len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[index], nil
+}
+
+// shuffle puts runes in a random order using Fisher-Yates
+func shuffle(runes []rune) error {
+	for index := len(runes) - 1; index > 0; index-- {
+		other, err := randomIndex(index + 1)
+		if err != nil {
+			return err
+		}
+		runes[index], runes[other] = runes[other], runes[index]
+	}
+	return nil
+}
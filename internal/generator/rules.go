@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+
+	"passenger-go-cli/internal/passwordrules"
+)
+
+// maxRuleAttempts bounds retries for max-consecutive, which rarely rejects anything
+const maxRuleAttempts = 100
+
+// GenerateForRules creates a passphrase the site rules accept, as close to
+// the preferred length as they allow
+func GenerateForRules(rules *passwordrules.Rules, preferred int) (string, error) {
+	length := rules.ClampLength(preferred)
+
+	for range maxRuleAttempts {
+		passphrase := make([]rune, 0, length)
+		for _, set := range rules.Required {
+			character, err := pick(set)
+			if err != nil {
+				return "", err
+			}
+			passphrase = append(passphrase, character)
+		}
+		for len(passphrase) < length {
+			character, err := pick(rules.Allowed)
+			if err != nil {
+				return "", err
+			}
+			passphrase = append(passphrase, character)
+		}
+		if err := shuffle(passphrase); err != nil {
+			return "", err
+		}
+
+		if rules.Check(string(passphrase)) == nil {
+			return string(passphrase), nil
+		}
+	}
+	return "", fmt.Errorf("could not generate a passphrase matching %q", rules.String())
+}
+
+// RulesEntropy estimates the bits of entropy of passphrases from GenerateForRules
+func RulesEntropy(rules *passwordrules.Rules, preferred int) float64 {
+	return float64(rules.ClampLength(preferred)) * math.Log2(float64(len(rules.Allowed)))
+}
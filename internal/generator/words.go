@@ -0,0 +1,194 @@
+package generator
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+)
+
+/*
+ * Diceware style passphrases made of random words, easier to type by hand.
+ *
+ * The EFF wordlists are embedded, see
+ * https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases
+ * (CC BY 3.0, Electronic Frontier Foundation).
+ * Custom wordlists are text files with one word per line, the dice rolls of
+ * the EFF format ("11111<TAB>abacus") are accepted and ignored.
+ */
+
+//go:embed wordlists/eff_large_wordlist.txt
+var effLongWordlist string
+
+//go:embed wordlists/eff_short_wordlist_2_0.txt
+var effShortWordlist string
+
+// Capitalization styles for word passphrases
+const (
+	CapitalizeLower  = "lower"
+	CapitalizeTitle  = "title"
+	CapitalizeUpper  = "upper"
+	CapitalizeRandom = "random" // Every word lower or title case, one bit per word
+)
+
+// WordOptions describe a word passphrase
+type WordOptions struct {
+	Words      int
+	Wordlist   []string
+	Separator  string
+	Capitalize string
+
+	// Digit and Symbol append one random character to a random word
+	Digit  bool
+	Symbol bool
+}
+
+// LoadWordlist returns the embedded "long" or "short" EFF list, or reads a file
+func LoadWordlist(name string) ([]string, error) {
+	var content string
+	switch name {
+	case "", "long":
+		content = effLongWordlist
+	case "short":
+		content = effShortWordlist
+	default:
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		content = string(data)
+	}
+
+	seen := map[string]bool{}
+	var words []string
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		word := fields[len(fields)-1]
+		if len(fields) > 2 || (len(fields) == 2 && !isRoll(fields[0])) {
+			return nil, fmt.Errorf("wordlist line %q has more than one word", line)
+		}
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+
+	if len(words) < 2 {
+		return nil, fmt.Errorf("the wordlist needs at least two different words")
+	}
+	return words, nil
+}
+
+// isRoll reports whether field looks like the dice rolls of an EFF wordlist
+func isRoll(field string) bool {
+	for _, character := range field {
+		if character < '1' || character > '6' {
+			return false
+		}
+	}
+	return true
+}
+
+// GenerateWords creates a word passphrase following options
+func GenerateWords(options WordOptions) (string, error) {
+	if err := options.validate(); err != nil {
+		return "", err
+	}
+
+	words := make([]string, options.Words)
+	for index := range words {
+		position, err := randomIndex(len(options.Wordlist))
+		if err != nil {
+			return "", err
+		}
+		words[index] = options.Wordlist[position]
+
+		words[index], err = capitalize(words[index], options.Capitalize)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, extra := range options.extras() {
+		character, err := pick([]rune(extra))
+		if err != nil {
+			return "", err
+		}
+		position, err := randomIndex(len(words))
+		if err != nil {
+			return "", err
+		}
+		words[position] += string(character)
+	}
+
+	return strings.Join(words, options.Separator), nil
+}
+
+// WordsEntropy estimates the bits of entropy of passphrases generated with options
+func WordsEntropy(options WordOptions) (float64, error) {
+	if err := options.validate(); err != nil {
+		return 0, err
+	}
+
+	bits := float64(options.Words) * math.Log2(float64(len(options.Wordlist)))
+	if options.Capitalize == CapitalizeRandom {
+		bits += float64(options.Words)
+	}
+	for _, extra := range options.extras() {
+		bits += math.Log2(float64(len(extra))) + math.Log2(float64(options.Words))
+	}
+	return bits, nil
+}
+
+func (options WordOptions) validate() error {
+	if options.Words < 1 {
+		return fmt.Errorf("at least one word is needed")
+	}
+	if len(options.Wordlist) < 2 {
+		return fmt.Errorf("the wordlist needs at least two different words")
+	}
+	switch options.Capitalize {
+	case "", CapitalizeLower, CapitalizeTitle, CapitalizeUpper, CapitalizeRandom:
+		return nil
+	}
+	return fmt.Errorf("unknown capitalization %q, use lower, title, upper or random", options.Capitalize)
+}
+
+// extras are the character sets inserted into the words
+func (options WordOptions) extras() []string {
+	var extras []string
+	if options.Digit {
+		extras = append(extras, Digits)
+	}
+	if options.Symbol {
+		extras = append(extras, Symbols)
+	}
+	return extras
+}
+
+func capitalize(word, style string) (string, error) {
+	switch style {
+	case CapitalizeTitle:
+		return title(word), nil
+	case CapitalizeUpper:
+		return strings.ToUpper(word), nil
+	case CapitalizeRandom:
+		coin, err := randomIndex(2)
+		if err != nil || coin == 0 {
+			return word, err
+		}
+		return title(word), nil
+	}
+	return word, nil
+}
+
+func title(word string) string {
+	runes := []rune(word)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
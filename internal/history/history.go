@@ -0,0 +1,187 @@
+package history
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"passenger-go-cli/internal/auth"
+	"passenger-go-cli/internal/config"
+)
+
+/*
+ * Local history of replaced passphrases, so a rotation can be rolled back
+ * when the change on the site failed. The whole file is encrypted with
+ * AES-256-GCM, the key is kept in the system keyring and never on disk.
+ */
+
+// FileName is the encrypted history in the config directory
+const FileName = "history.enc"
+
+// keepPerAccount bounds how many old passphrases are kept for each account
+const keepPerAccount = 10
+
+type Entry struct {
+	Server     string    `json:"server"`
+	AccountID  string    `json:"account_id"`
+	Platform   string    `json:"platform"`
+	Identifier string    `json:"identifier"`
+	Passphrase string    `json:"passphrase"`
+	Reason     string    `json:"reason"` // What replaced it, like "rotate" or "rollback"
+	Time       time.Time `json:"time"`
+}
+
+func path() (string, error) {
+	directory, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(directory, FileName), nil
+}
+
+func newCipher() (cipher.AEAD, error) {
+	key, err := auth.HistoryKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// load decrypts all entries, oldest first
+func load() ([]Entry, error) {
+	path, err := path()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(content) < aead.NonceSize() {
+		return nil, fmt.Errorf("%s is corrupt", path)
+	}
+	nonce, sealed := content[:aead.NonceSize()], content[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, []byte(FileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s, the key in the keyring does not match: %w", path, err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(plain, &entries); err != nil {
+		return nil, fmt.Errorf("%s is corrupt: %w", path, err)
+	}
+	return entries, nil
+}
+
+// save encrypts entries with a fresh nonce and replaces the file atomically
+func save(entries []Entry) error {
+	path, err := path()
+	if err != nil {
+		return err
+	}
+	aead, err := newCipher()
+	if err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := aead.Seal(nonce, nonce, plain, []byte(FileName))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, sealed, 0600); err != nil {
+		return err
+	}
+	return os.Rename(temporary, path)
+}
+
+// Push records a replaced passphrase, dropping the oldest ones of the account
+// beyond what is kept
+func Push(entry Entry) error {
+	entries, err := load()
+	if err != nil {
+		return err
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	entries = append(entries, entry)
+
+	same := func(other Entry) bool {
+		return other.Server == entry.Server && other.AccountID == entry.AccountID
+	}
+	excess := -keepPerAccount
+	for _, existing := range entries {
+		if same(existing) {
+			excess++
+		}
+	}
+
+	kept := make([]Entry, 0, len(entries))
+	for _, existing := range entries {
+		if excess > 0 && same(existing) {
+			excess--
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	return save(kept)
+}
+
+// List returns the entries of an account, newest first
+func List(server, accountID string) ([]Entry, error) {
+	entries, err := load()
+	if err != nil {
+		return nil, err
+	}
+	var matching []Entry
+	for index := len(entries) - 1; index >= 0; index-- {
+		if entries[index].Server == server && entries[index].AccountID == accountID {
+			matching = append(matching, entries[index])
+		}
+	}
+	return matching, nil
+}
+
+// Remove deletes an entry returned by List
+func Remove(entry Entry) error {
+	entries, err := load()
+	if err != nil {
+		return err
+	}
+	kept := entries[:0]
+	for _, existing := range entries {
+		if existing.Server == entry.Server && existing.AccountID == entry.AccountID && existing.Time.Equal(entry.Time) {
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	return save(kept)
+}
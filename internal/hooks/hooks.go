@@ -0,0 +1,77 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+/*
+ * Rotation hooks change a credential in an external system. The hook is an
+ * executable that gets a Payload as JSON on stdin and exits 0 when the change
+ * was made. Passphrases are never passed as arguments or environment, where
+ * other processes could read them.
+ */
+
+// Actions a hook is run for
+const (
+	ActionRotate   = "rotate"   // Change to a newly generated passphrase
+	ActionRollback = "rollback" // Change back to a passphrase from the history
+	ActionRevert   = "revert"   // Undo the previous call, the vault could not be updated
+)
+
+type Account struct {
+	ID         string `json:"id"`
+	Platform   string `json:"platform"`
+	Identifier string `json:"identifier"`
+	URL        string `json:"url"`
+}
+
+// Payload is written to the hook's stdin
+type Payload struct {
+	Action        string  `json:"action"`
+	Server        string  `json:"server"`
+	Account       Account `json:"account"`
+	OldPassphrase string  `json:"old_passphrase"`
+	NewPassphrase string  `json:"new_passphrase"`
+}
+
+// ErrTimeout is wrapped by Run when the hook was killed after its timeout.
+// The hook may have changed the credential before that.
+var ErrTimeout = errors.New("timed out")
+
+// Run executes the hook and waits at most timeout, its output goes to output
+func Run(executable string, payload Payload, timeout time.Duration, output io.Writer) error {
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	command := exec.CommandContext(ctx, executable)
+	command.Stdin = bytes.NewReader(input)
+	command.Stdout = output
+	command.Stderr = output
+	// Children of a killed hook may keep its output open, don't wait for them
+	command.WaitDelay = time.Second
+
+	err = command.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("hook %s %w after %s", executable, ErrTimeout, timeout)
+	}
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return fmt.Errorf("hook %s exited with %d", executable, exitError.ExitCode())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run hook %s: %w", executable, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"passenger-go-cli/internal/config"
+)
+
+/*
+ * Local facts about accounts the server does not keep, like when their
+ * passphrase last changed. Stored as metadata.json in the config directory,
+ * keyed by server URL and account ID so several vaults do not mix.
+ */
+
+// FileName is the metadata file in the config directory
+const FileName = "metadata.json"
+
+// Sources of a change
+const (
+	SourceCreated    = "created"
+	SourceUpdated    = "updated"
+	SourceRotated    = "rotated"
+	SourceRolledBack = "rolled back"
+	SourceSeeded     = "seeded" // Not a change, the passphrase is at least this old
+)
+
+// Record is what is known about the passphrase of one account
+type Record struct {
+	Changed time.Time `json:"changed"`
+	Source  string    `json:"source"`
+}
+
+// Store holds the records of all servers, load it with Load and write it back with Save
+type Store struct {
+	Servers map[string]map[string]Record `json:"servers"`
+}
+
+func path() (string, error) {
+	directory, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(directory, FileName), nil
+}
+
+// Load reads the store, an empty one when the file does not exist yet
+func Load() (*Store, error) {
+	store := &Store{Servers: map[string]map[string]Record{}}
+
+	path, err := path()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, store); err != nil {
+		return nil, fmt.Errorf("%s is corrupt: %w", path, err)
+	}
+	if store.Servers == nil {
+		store.Servers = map[string]map[string]Record{}
+	}
+	return store, nil
+}
+
+// Save writes the store atomically
+func (store *Store) Save() error {
+	path, err := path()
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(temporary, path)
+}
+
+// Get returns the record of an account
+func (store *Store) Get(server, accountID string) (Record, bool) {
+	record, ok := store.Servers[server][accountID]
+	return record, ok
+}
+
+// Set replaces the record of an account
+func (store *Store) Set(server, accountID string, record Record) {
+	if store.Servers[server] == nil {
+		store.Servers[server] = map[string]Record{}
+	}
+	store.Servers[server][accountID] = record
+}
+
+// Seed records that the passphrase existed at changed, without overwriting
+// what is already known. It reports whether a record was added.
+func (store *Store) Seed(server, accountID string, changed time.Time) bool {
+	if _, ok := store.Get(server, accountID); ok {
+		return false
+	}
+	store.Set(server, accountID, Record{Changed: changed.UTC(), Source: SourceSeeded})
+	return true
+}
+
+// Remove forgets an account, like after it was deleted
+func (store *Store) Remove(server, accountID string) {
+	delete(store.Servers[server], accountID)
+}
+
+// RecordChange stores that the passphrase of an account changed just now
+func RecordChange(server, accountID, source string) error {
+	store, err := Load()
+	if err != nil {
+		return err
+	}
+	store.Set(server, accountID, Record{Changed: time.Now().UTC(), Source: source})
+	return store.Save()
+}
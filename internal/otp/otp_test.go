@@ -0,0 +1,175 @@
+package otp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The secrets of the RFC test vectors, as ASCII
+const (
+	rfcSHA1   = "12345678901234567890"
+	rfcSHA256 = "12345678901234567890123456789012"
+	rfcSHA512 = "1234567890123456789012345678901234567890123456789012345678901234"
+)
+
+// RFC 4226 Appendix D
+func TestHOTPVectors(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, code := range want {
+		key := &Key{Type: TypeHOTP, Secret: []byte(rfcSHA1), Algorithm: "SHA1", Digits: 6, Counter: uint64(counter)}
+		if got := key.Code(time.Time{}); got != code {
+			t.Errorf("counter %d: Code() = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+// RFC 6238 Appendix B
+func TestTOTPVectors(t *testing.T) {
+	secrets := map[string]string{"SHA1": rfcSHA1, "SHA256": rfcSHA256, "SHA512": rfcSHA512}
+	tests := []struct {
+		unix int64
+		want map[string]string // By algorithm
+	}{
+		{59, map[string]string{"SHA1": "94287082", "SHA256": "46119246", "SHA512": "90693936"}},
+		{1111111109, map[string]string{"SHA1": "07081804", "SHA256": "68084774", "SHA512": "25091201"}},
+		{1111111111, map[string]string{"SHA1": "14050471", "SHA256": "67062674", "SHA512": "99943326"}},
+		{1234567890, map[string]string{"SHA1": "89005924", "SHA256": "91819424", "SHA512": "93441116"}},
+		{2000000000, map[string]string{"SHA1": "69279037", "SHA256": "90698825", "SHA512": "38618901"}},
+		{20000000000, map[string]string{"SHA1": "65353130", "SHA256": "77737706", "SHA512": "47863826"}},
+	}
+
+	for _, test := range tests {
+		for algorithm, want := range test.want {
+			key := &Key{Type: TypeTOTP, Secret: []byte(secrets[algorithm]), Algorithm: algorithm, Digits: 8, Period: 30}
+			if got := key.Code(time.Unix(test.unix, 0)); got != want {
+				t.Errorf("%s at %d: Code() = %s, want %s", algorithm, test.unix, got, want)
+			}
+		}
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	key := &Key{Type: TypeTOTP, Period: 30}
+	for unix, want := range map[int64]time.Duration{0: 30 * time.Second, 59: time.Second, 61: 29 * time.Second} {
+		if got := key.Remaining(time.Unix(unix, 0)); got != want {
+			t.Errorf("Remaining at %d = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want Key
+	}{
+		{
+			name: "defaults",
+			uri:  "otpauth://totp/alice@example.com?secret=JBSWY3DPEHPK3PXP",
+			want: Key{Type: TypeTOTP, Account: "alice@example.com", Secret: []byte("Hello!\xde\xad\xbe\xef"),
+				Algorithm: "SHA1", Digits: 6, Period: 30},
+		},
+		{
+			name: "issuer in the label",
+			uri:  "otpauth://totp/ACME%20Co:john@example.com?secret=JBSWY3DPEHPK3PXP",
+			want: Key{Type: TypeTOTP, Issuer: "ACME Co", Account: "john@example.com", Secret: []byte("Hello!\xde\xad\xbe\xef"),
+				Algorithm: "SHA1", Digits: 6, Period: 30},
+		},
+		{
+			name: "issuer parameter wins over the label",
+			uri:  "otpauth://totp/Old:john?secret=JBSWY3DPEHPK3PXP&issuer=New",
+			want: Key{Type: TypeTOTP, Issuer: "New", Account: "john", Secret: []byte("Hello!\xde\xad\xbe\xef"),
+				Algorithm: "SHA1", Digits: 6, Period: 30},
+		},
+		{
+			name: "all parameters",
+			uri:  "otpauth://TOTP/Bank:me?secret=gezd-gnbv&algorithm=sha512&digits=8&period=60",
+			want: Key{Type: TypeTOTP, Issuer: "Bank", Account: "me", Secret: []byte("12345"),
+				Algorithm: "SHA512", Digits: 8, Period: 60},
+		},
+		{
+			name: "hotp",
+			uri:  "otpauth://hotp/me?secret=GEZDGNBV&counter=42",
+			want: Key{Type: TypeHOTP, Account: "me", Secret: []byte("12345"),
+				Algorithm: "SHA1", Digits: 6, Period: 30, Counter: 42},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := Parse(test.uri)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", test.uri, err)
+			}
+			if !reflect.DeepEqual(*key, test.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", test.uri, *key, test.want)
+			}
+
+			again, err := Parse(key.URI())
+			if err != nil {
+				t.Fatalf("Parse(URI()) of %q failed: %v", key.URI(), err)
+			}
+			if !reflect.DeepEqual(again, key) {
+				t.Errorf("Parse(%q) = %+v, want %+v", key.URI(), *again, *key)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		uri     string
+		message string
+	}{
+		{"https://example.com/?secret=GEZDGNBV", "not an otpauth"},
+		{"otpauth://motp/me?secret=GEZDGNBV", "unknown type"},
+		{"otpauth://totp/me", "secret is empty"},
+		{"otpauth://totp/me?secret=1234", "not valid base32"},
+		{"otpauth://totp/me?secret=GEZDGNBV&digits=5", "digits must be"},
+		{"otpauth://totp/me?secret=GEZDGNBV&digits=11", "digits must be"},
+		{"otpauth://totp/me?secret=GEZDGNBV&digits=six", "digits must be"},
+		{"otpauth://totp/me?secret=GEZDGNBV&period=0", "period must be"},
+		{"otpauth://totp/me?secret=GEZDGNBV&period=-30", "period must be"},
+		{"otpauth://totp/me?secret=GEZDGNBV&algorithm=MD5", "unknown algorithm"},
+		{"otpauth://hotp/me?secret=GEZDGNBV", "need a counter"},
+		{"otpauth://hotp/me?secret=GEZDGNBV&counter=-1", "counter must be"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.uri)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Parse(%q) = %v, want an error containing %q", test.uri, err, test.message)
+		}
+	}
+}
+
+func TestDecodeSecret(t *testing.T) {
+	want := []byte("Hello!\xde\xad\xbe\xef")
+	for _, secret := range []string{"JBSWY3DPEHPK3PXP", "jbsw y3dp ehpk 3pxp", "JBSW-Y3DP-EHPK-3PXP", "jbswY3dp-ehpk 3pxp", "JBSWY3DPEHPK3PXP===="} {
+		got, err := DecodeSecret(secret)
+		if err != nil {
+			t.Errorf("DecodeSecret(%q) failed: %v", secret, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DecodeSecret(%q) = %q, want %q", secret, got, want)
+		}
+	}
+
+	for _, secret := range []string{"", " - ", "JBSWY3DP1"} {
+		if _, err := DecodeSecret(secret); err == nil {
+			t.Errorf("DecodeSecret(%q) succeeded, want an error", secret)
+		}
+	}
+}
+
+func TestFindURIs(t *testing.T) {
+	notes := "Recovery codes in the safe\notpauth://totp/A:b?secret=GEZDGNBV\nand otpauth://hotp/c?secret=GEZDGNBV&counter=1 too"
+	want := []string{"otpauth://totp/A:b?secret=GEZDGNBV", "otpauth://hotp/c?secret=GEZDGNBV&counter=1"}
+	if got := FindURIs(notes); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindURIs() = %q, want %q", got, want)
+	}
+}
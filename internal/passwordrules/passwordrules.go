@@ -0,0 +1,337 @@
+package passwordrules
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+ * Parses password rules in the syntax Apple uses for the HTML passwordrules
+ * attribute, see https://developer.apple.com/password-rules/
+ *
+ * Rules are separated by ";", each is "name: value":
+ * - required: classes       at least one character of these classes
+ * - allowed: classes        characters of these classes may be used
+ * - minlength: n            at least n characters
+ * - maxlength: n            at most n characters
+ * - max-consecutive: n      no character repeated more than n times in a row
+ *
+ * Classes are separated by ",": upper, lower, digit, special,
+ * ascii-printable, unicode, or custom characters in brackets like [-_.].
+ * Repeated minlength, maxlength and max-consecutive rules keep the strictest
+ * value. Without required or allowed rules every ASCII printable character
+ * is allowed.
+ */
+
+// Named character classes. Space is left out of special and ascii-printable,
+// few sites accept it, add it with a custom class "[ ]" when they do.
+var classes = map[string]string{
+	"upper":           "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"lower":           "abcdefghijklmnopqrstuvwxyz",
+	"digit":           "0123456789",
+	"special":         "-~!@#$%^&*_+=`|(){}[:;\"'<>,.?]/\\",
+	"ascii-printable": asciiPrintable(),
+	"unicode":         asciiPrintable(), // Generating arbitrary Unicode helps nobody
+}
+
+func asciiPrintable() string {
+	var builder strings.Builder
+	for character := '!'; character <= '~'; character++ {
+		builder.WriteRune(character)
+	}
+	return builder.String()
+}
+
+// Rules is a parsed set of password rules
+type Rules struct {
+	Required       [][]rune // Each set needs at least one character
+	Allowed        []rune   // Every usable character, including the required ones
+	MinLength      int      // Zero when not set
+	MaxLength      int      // Zero when not set
+	MaxConsecutive int      // Zero when not set
+}
+
+// Error describes why a rule string could not be parsed
+type Error struct {
+	Rule    string // The rule as written, trimmed
+	Message string
+}
+
+func (err *Error) Error() string {
+	if err.Rule == "" {
+		return err.Message
+	}
+	return fmt.Sprintf("%s (in %q)", err.Message, err.Rule)
+}
+
+// Parse reads a rule string like "minlength: 12; required: lower; allowed: [-_]"
+func Parse(text string) (*Rules, error) {
+	rules := &Rules{}
+	var allowed []rune
+	sawClasses := false
+
+	for _, rule := range splitRules(text) {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(rule, ":")
+		if !found {
+			return nil, &Error{Rule: rule, Message: "expected \"name: value\""}
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		switch name {
+		case "required", "allowed":
+			set, err := parseClasses(value)
+			if err != nil {
+				return nil, &Error{Rule: rule, Message: err.Error()}
+			}
+			sawClasses = true
+			allowed = append(allowed, set...)
+			if name == "required" {
+				rules.Required = append(rules.Required, set)
+			}
+		case "minlength", "maxlength", "max-consecutive":
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 0 {
+				return nil, &Error{Rule: rule, Message: "expected a whole number that is not negative"}
+			}
+			if number == 0 && name != "minlength" {
+				return nil, &Error{Rule: rule, Message: "must be at least 1"}
+			}
+			switch name {
+			case "minlength":
+				rules.MinLength = max(rules.MinLength, number)
+			case "maxlength":
+				rules.MaxLength = strictest(rules.MaxLength, number)
+			case "max-consecutive":
+				rules.MaxConsecutive = strictest(rules.MaxConsecutive, number)
+			}
+		default:
+			return nil, &Error{Rule: rule, Message: fmt.Sprintf("unknown rule %q, expected required, allowed, minlength, maxlength or max-consecutive", name)}
+		}
+	}
+
+	if !sawClasses {
+		allowed = []rune(classes["ascii-printable"])
+	}
+	rules.Allowed = unique(allowed)
+
+	if rules.MaxLength > 0 && rules.MinLength > rules.MaxLength {
+		return nil, &Error{Message: fmt.Sprintf("minlength %d is greater than maxlength %d", rules.MinLength, rules.MaxLength)}
+	}
+	if rules.MaxLength > 0 && len(rules.Required) > rules.MaxLength {
+		return nil, &Error{Message: fmt.Sprintf("%d required classes do not fit in maxlength %d", len(rules.Required), rules.MaxLength)}
+	}
+	return rules, nil
+}
+
+// splitRules splits at ";" outside of custom classes, so "allowed: [;:]"
+// stays one rule. Like parseCustomClass a "]" right after "[" is literal.
+func splitRules(text string) []string {
+	var rules []string
+	runes := []rune(text)
+	start, classStart, inClass := 0, 0, false
+
+	for index, character := range runes {
+		switch {
+		case inClass:
+			if character == ']' && index > classStart+1 {
+				inClass = false
+			}
+		case character == '[':
+			inClass, classStart = true, index
+		case character == ';':
+			rules = append(rules, string(runes[start:index]))
+			start = index + 1
+		}
+	}
+	return append(rules, string(runes[start:]))
+}
+
+// strictest keeps the lower of two limits where zero means no limit
+func strictest(current, number int) int {
+	if current == 0 {
+		return number
+	}
+	return min(current, number)
+}
+
+// parseClasses reads a comma separated list of named and custom classes
+func parseClasses(value string) ([]rune, error) {
+	var set []rune
+	runes := []rune(value)
+	index := 0
+
+	for {
+		for index < len(runes) && unicode.IsSpace(runes[index]) {
+			index++
+		}
+		if index == len(runes) {
+			return nil, fmt.Errorf("expected a character class")
+		}
+
+		if runes[index] == '[' {
+			custom, next, err := parseCustomClass(runes, index)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, custom...)
+			index = next
+		} else {
+			start := index
+			for index < len(runes) && runes[index] != ',' && !unicode.IsSpace(runes[index]) {
+				index++
+			}
+			name := strings.ToLower(string(runes[start:index]))
+			characters, ok := classes[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown character class %q, expected upper, lower, digit, special, ascii-printable, unicode or [characters]", name)
+			}
+			set = append(set, []rune(characters)...)
+		}
+
+		for index < len(runes) && unicode.IsSpace(runes[index]) {
+			index++
+		}
+		if index == len(runes) {
+			return unique(set), nil
+		}
+		if runes[index] != ',' {
+			return nil, fmt.Errorf("expected \",\" between character classes, found %q", runes[index])
+		}
+		index++
+	}
+}
+
+// parseCustomClass reads "[...]" starting at the opening bracket. A "]" right
+// after the opening bracket is taken literally, so "[]-]" means "]" and "-".
+func parseCustomClass(runes []rune, start int) ([]rune, int, error) {
+	var set []rune
+	for index := start + 1; index < len(runes); index++ {
+		character := runes[index]
+		if character == ']' && index > start+1 {
+			if len(set) == 0 {
+				return nil, 0, fmt.Errorf("custom character class has no printable characters")
+			}
+			return set, index + 1, nil
+		}
+		if unicode.IsPrint(character) {
+			set = append(set, character)
+		}
+	}
+	return nil, 0, fmt.Errorf("custom character class is missing its closing \"]\"")
+}
+
+func unique(set []rune) []rune {
+	set = slices.Clone(set)
+	slices.Sort(set)
+	return slices.Compact(set)
+}
+
+// ClampLength returns the length closest to preferred that the rules accept
+func (rules *Rules) ClampLength(preferred int) int {
+	length := max(preferred, rules.MinLength, len(rules.Required), 1)
+	if rules.MaxLength > 0 {
+		length = min(length, rules.MaxLength)
+	}
+	return length
+}
+
+// Check reports the first rule a passphrase breaks, or nil
+func (rules *Rules) Check(passphrase string) error {
+	runes := []rune(passphrase)
+
+	if len(runes) < rules.MinLength {
+		return fmt.Errorf("needs at least %d characters", rules.MinLength)
+	}
+	if rules.MaxLength > 0 && len(runes) > rules.MaxLength {
+		return fmt.Errorf("may have at most %d characters", rules.MaxLength)
+	}
+	for _, character := range runes {
+		if _, found := slices.BinarySearch(rules.Allowed, character); !found {
+			return fmt.Errorf("%q is not an allowed character", character)
+		}
+	}
+	for _, set := range rules.Required {
+		if !slices.ContainsFunc(runes, func(character rune) bool { return slices.Contains(set, character) }) {
+			return fmt.Errorf("needs one of %q", string(set))
+		}
+	}
+	if rules.MaxConsecutive > 0 && longestRun(runes) > rules.MaxConsecutive {
+		return fmt.Errorf("repeats a character more than %d times in a row", rules.MaxConsecutive)
+	}
+	return nil
+}
+
+// longestRun returns the length of the longest run of one repeated character
+func longestRun(runes []rune) int {
+	longest, current := 0, 0
+	for index, character := range runes {
+		if index > 0 && character == runes[index-1] {
+			current++
+		} else {
+			current = 1
+		}
+		longest = max(longest, current)
+	}
+	return longest
+}
+
+// String formats the rules in canonical form
+func (rules *Rules) String() string {
+	var parts []string
+	if rules.MinLength > 0 {
+		parts = append(parts, "minlength: "+strconv.Itoa(rules.MinLength))
+	}
+	if rules.MaxLength > 0 {
+		parts = append(parts, "maxlength: "+strconv.Itoa(rules.MaxLength))
+	}
+	for _, set := range rules.Required {
+		parts = append(parts, "required: "+describe(set))
+	}
+	parts = append(parts, "allowed: "+describe(rules.Allowed))
+	if rules.MaxConsecutive > 0 {
+		parts = append(parts, "max-consecutive: "+strconv.Itoa(rules.MaxConsecutive))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describe writes a character set with class names where a whole class is included
+func describe(set []rune) string {
+	remaining := slices.Clone(set)
+	var names []string
+
+	for _, name := range []string{"ascii-printable", "upper", "lower", "digit", "special"} {
+		class := []rune(classes[name])
+		if !containsAll(remaining, class) {
+			continue
+		}
+		names = append(names, name)
+		remaining = slices.DeleteFunc(remaining, func(character rune) bool { return slices.Contains(class, character) })
+	}
+
+	if len(remaining) > 0 {
+		// "]" goes first so it is not read as the end of the class
+		if index := slices.Index(remaining, ']'); index > 0 {
+			remaining = append([]rune{']'}, slices.Delete(remaining, index, index+1)...)
+		}
+		names = append(names, "["+string(remaining)+"]")
+	}
+	return strings.Join(names, ", ")
+}
+
+func containsAll(set, class []rune) bool {
+	for _, character := range class {
+		if !slices.Contains(set, character) {
+			return false
+		}
+	}
+	return true
+}
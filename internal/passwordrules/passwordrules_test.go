@@ -0,0 +1,223 @@
+package passwordrules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func runes(text string) []rune {
+	return unique([]rune(text))
+}
+
+func TestParse(t *testing.T) {
+	upper := classes["upper"]
+	lower := classes["lower"]
+	digit := classes["digit"]
+
+	tests := []struct {
+		name  string
+		rules string
+		want  Rules
+	}{
+		{
+			name:  "empty allows ascii printable",
+			rules: "",
+			want:  Rules{Allowed: runes(classes["ascii-printable"])},
+		},
+		{
+			name:  "lengths only",
+			rules: "minlength: 8; maxlength: 64",
+			want:  Rules{MinLength: 8, MaxLength: 64, Allowed: runes(classes["ascii-printable"])},
+		},
+		{
+			name:  "required adds to allowed",
+			rules: "required: upper; required: digit",
+			want:  Rules{Required: [][]rune{runes(upper), runes(digit)}, Allowed: runes(upper + digit)},
+		},
+		{
+			name:  "allowed",
+			rules: "allowed: lower, [-_.]",
+			want:  Rules{Allowed: runes(lower + "-_.")},
+		},
+		{
+			name:  "max-consecutive",
+			rules: "max-consecutive: 2",
+			want:  Rules{MaxConsecutive: 2, Allowed: runes(classes["ascii-printable"])},
+		},
+		{
+			name:  "strictest repeated limits",
+			rules: "minlength: 8; minlength: 12; maxlength: 40; maxlength: 30; max-consecutive: 3; max-consecutive: 2",
+			want:  Rules{MinLength: 12, MaxLength: 30, MaxConsecutive: 2, Allowed: runes(classes["ascii-printable"])},
+		},
+		{
+			name:  "special",
+			rules: "allowed: special",
+			want:  Rules{Allowed: runes(classes["special"])},
+		},
+		{
+			name:  "unicode is ascii printable",
+			rules: "allowed: unicode",
+			want:  Rules{Allowed: runes(classes["ascii-printable"])},
+		},
+		{
+			name:  "dash class",
+			rules: "allowed: [-]",
+			want:  Rules{Allowed: []rune{'-'}},
+		},
+		{
+			name:  "closing bracket first is literal",
+			rules: "allowed: []]",
+			want:  Rules{Allowed: []rune{']'}},
+		},
+		{
+			name:  "closing bracket and dash",
+			rules: "allowed: []-]",
+			want:  Rules{Allowed: runes("]-")},
+		},
+		{
+			name:  "semicolon class",
+			rules: "required: upper, [;]",
+			want:  Rules{Required: [][]rune{runes(upper + ";")}, Allowed: runes(upper + ";")},
+		},
+		{
+			name:  "semicolon and colon class",
+			rules: "allowed: [;:]; minlength: 4",
+			want:  Rules{MinLength: 4, Allowed: runes(";:")},
+		},
+		{
+			name:  "space class",
+			rules: "allowed: lower, [ ]",
+			want:  Rules{Allowed: runes(lower + " ")},
+		},
+		{
+			name:  "casing and whitespace",
+			rules: "  MinLength :  10 ;;  REQUIRED:UPPER ,  Digit ;  ",
+			want:  Rules{MinLength: 10, Required: [][]rune{runes(upper + digit)}, Allowed: runes(upper + digit)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.rules)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", test.rules, err)
+			}
+			if !reflect.DeepEqual(*got, test.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", test.rules, *got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		rules   string
+		message string
+	}{
+		{"minlength 8", "expected \"name: value\""},
+		{"length: 8", "unknown rule"},
+		{"minlength: eight", "whole number"},
+		{"minlength: -1", "whole number"},
+		{"maxlength: 0", "at least 1"},
+		{"max-consecutive: 0", "at least 1"},
+		{"required:", "expected a character class"},
+		{"required: upper,", "expected a character class"},
+		{"required: capitals", "unknown character class"},
+		{"required: upper digit", "expected \",\""},
+		{"allowed: [abc", "missing its closing"},
+		{"allowed: [;", "missing its closing"},
+		{"allowed: []", "missing its closing"},
+		{"allowed: [\t]", "no printable characters"},
+		{"minlength: 20; maxlength: 10", "greater than maxlength"},
+		{"maxlength: 1; required: upper; required: digit", "do not fit"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.rules, func(t *testing.T) {
+			_, err := Parse(test.rules)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want an error", test.rules)
+			}
+			if !strings.Contains(err.Error(), test.message) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", test.rules, err, test.message)
+			}
+		})
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		rules string
+		want  string
+	}{
+		{"", "allowed: ascii-printable"},
+		{"required: upper; allowed: [;:]", "required: upper; allowed: upper, [:;]"},
+		{"minlength: 12; maxlength: 20; required: lower; required: digit; max-consecutive: 2",
+			"minlength: 12; maxlength: 20; required: lower; required: digit; allowed: lower, digit; max-consecutive: 2"},
+		{"allowed: []-], upper", "allowed: upper, []-]"},
+		{"required: [;]; allowed: lower, special", "required: [;]; allowed: lower, special"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.rules, func(t *testing.T) {
+			parsed, err := Parse(test.rules)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", test.rules, err)
+			}
+			formatted := parsed.String()
+			if formatted != test.want {
+				t.Errorf("String() = %q, want %q", formatted, test.want)
+			}
+
+			again, err := Parse(formatted)
+			if err != nil {
+				t.Fatalf("Parse(String()) of %q failed: %v", formatted, err)
+			}
+			if !reflect.DeepEqual(again, parsed) {
+				t.Errorf("Parse(%q) = %+v, want %+v", formatted, *again, *parsed)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	rules, err := Parse("minlength: 6; maxlength: 10; required: upper; required: digit; allowed: lower, [;]; max-consecutive: 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		passphrase string
+		message    string // Empty when the passphrase is accepted
+	}{
+		{"Abc;12", ""},
+		{"Ab1", "at least 6"},
+		{"Abcdefgh123", "at most 10"},
+		{"Abc-123", "not an allowed character"},
+		{"abcd12", "needs one of"},
+		{"Abbb12", "more than 2 times"},
+	}
+
+	for _, test := range tests {
+		err := rules.Check(test.passphrase)
+		switch {
+		case test.message == "" && err != nil:
+			t.Errorf("Check(%q) = %v, want no error", test.passphrase, err)
+		case test.message != "" && (err == nil || !strings.Contains(err.Error(), test.message)):
+			t.Errorf("Check(%q) = %v, want an error containing %q", test.passphrase, err, test.message)
+		}
+	}
+}
+
+func TestClampLength(t *testing.T) {
+	rules, err := Parse("minlength: 8; maxlength: 16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for preferred, want := range map[int]int{0: 8, 12: 12, 32: 16} {
+		if got := rules.ClampLength(preferred); got != want {
+			t.Errorf("ClampLength(%d) = %d, want %d", preferred, got, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"passenger-go-cli/internal/config"
+)
+
+// OverrideLogName is the append only log of policy overrides in the config directory
+const OverrideLogName = "policy-overrides.log"
+
+// Override records a violation that was let through with --override-policy,
+// passphrases are never written
+type Override struct {
+	Time       time.Time `json:"time"`
+	User       string    `json:"user,omitempty"`
+	Command    string    `json:"command"`
+	Reason     string    `json:"reason"`
+	AccountID  string    `json:"account_id,omitempty"`
+	Platform   string    `json:"platform,omitempty"`
+	Identifier string    `json:"identifier,omitempty"`
+	Violations []string  `json:"violations"`
+}
+
+// LogOverride appends a JSON line to the override log
+func LogOverride(command, reason string, account Account, violations []Violation) error {
+	directory, err := config.GetConfigDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+
+	entry := Override{
+		Time:       time.Now().UTC(),
+		Command:    command,
+		Reason:     reason,
+		AccountID:  account.ID,
+		Platform:   account.Platform,
+		Identifier: account.Identifier,
+	}
+	if current, err := user.Current(); err == nil {
+		entry.User = current.Username
+	}
+	for _, violation := range violations {
+		entry.Violations = append(entry.Violations, violation.String())
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filepath.Join(directory, OverrideLogName), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
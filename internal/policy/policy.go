@@ -0,0 +1,299 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"passenger-go-cli/internal/config"
+	"passenger-go-cli/internal/resolver"
+	"passenger-go-cli/internal/strength"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+ * Client side password policy, read from policy.yaml in the config directory:
+ *
+ *   rule_sets:
+ *     - name: production
+ *       match: ["aws", "*.prod.example.com"]
+ *       min_length: 16
+ *       min_strength: 70
+ *       require: [lower, upper, digit]
+ *       no_reuse: true
+ *       max_age: 90d
+ *     - name: everything
+ *       min_length: 12
+ *
+ * A rule set applies to accounts whose lowercase platform or URL host matches
+ * one of its globs, or to every account without match. All rule sets that
+ * apply are enforced.
+ */
+
+// FileName is the name of the policy file in the config directory
+const FileName = "policy.yaml"
+
+// Character classes usable in require
+var classes = map[string]func(rune) bool{
+	"lower": unicode.IsLower,
+	"upper": unicode.IsUpper,
+	"digit": unicode.IsDigit,
+	"symbol": func(character rune) bool {
+		return !unicode.IsLetter(character) && !unicode.IsDigit(character) && !unicode.IsSpace(character)
+	},
+}
+
+type Policy struct {
+	RuleSets []RuleSet `yaml:"rule_sets"`
+}
+
+type RuleSet struct {
+	Name        string   `yaml:"name"`
+	Match       []string `yaml:"match"`
+	MinLength   int      `yaml:"min_length"`
+	MaxLength   int      `yaml:"max_length"`
+	MinStrength int      `yaml:"min_strength"`
+	Require     []string `yaml:"require"`
+	NoReuse     bool     `yaml:"no_reuse"` // Not shared with another account this rule set applies to
+	MaxAge      string   `yaml:"max_age"`  // Like 90d, 12w or 1y, see the due command
+
+	maxAge time.Duration
+}
+
+// Account is what the policy needs to know about an account
+type Account struct {
+	ID         string
+	Platform   string
+	Identifier string
+	URL        string
+}
+
+// Peer is another account with its passphrase, for no_reuse
+type Peer struct {
+	Account    Account
+	Passphrase string
+}
+
+// Violation is a rule of a rule set the passphrase breaks
+type Violation struct {
+	RuleSet string
+	Message string
+}
+
+func (violation Violation) String() string {
+	return violation.RuleSet + ": " + violation.Message
+}
+
+// Path returns where the policy file is read from
+func Path() (string, error) {
+	directory, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(directory, FileName), nil
+}
+
+// Load reads the policy file, a nil policy means there is none
+func Load() (*Policy, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Parse reads a policy from YAML, unknown keys are errors so typos do not
+// silently weaken the policy
+func Parse(content []byte) (*Policy, error) {
+	policy := &Policy{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	for index := range policy.RuleSets {
+		ruleSet := &policy.RuleSets[index]
+		if ruleSet.Name == "" {
+			ruleSet.Name = fmt.Sprintf("rule set %d", index+1)
+		}
+		for position, pattern := range ruleSet.Match {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%s: invalid pattern %q: %w", ruleSet.Name, pattern, err)
+			}
+			ruleSet.Match[position] = pattern
+		}
+		for _, class := range ruleSet.Require {
+			if classes[class] == nil {
+				return nil, fmt.Errorf("%s: unknown character class %q, expected lower, upper, digit or symbol", ruleSet.Name, class)
+			}
+		}
+		if ruleSet.MaxLength > 0 && ruleSet.MaxLength < ruleSet.MinLength {
+			return nil, fmt.Errorf("%s: max_length is below min_length", ruleSet.Name)
+		}
+		if ruleSet.MaxAge != "" {
+			age, err := ParseAge(ruleSet.MaxAge)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid max_age: %w", ruleSet.Name, err)
+			}
+			ruleSet.maxAge = age
+		}
+	}
+	return policy, nil
+}
+
+// Applies reports whether the rule set covers account
+func (ruleSet RuleSet) Applies(account Account) bool {
+	if len(ruleSet.Match) == 0 {
+		return true
+	}
+
+	candidates := []string{strings.ToLower(strings.TrimSpace(account.Platform))}
+	if host := resolver.Host(account.URL); host != "" {
+		candidates = append(candidates, host)
+	}
+	for _, pattern := range ruleSet.Match {
+		for _, candidate := range candidates {
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RuleSetsFor returns the rule sets that apply to account
+func (policy *Policy) RuleSetsFor(account Account) []RuleSet {
+	if policy == nil {
+		return nil
+	}
+	var ruleSets []RuleSet
+	for _, ruleSet := range policy.RuleSets {
+		if ruleSet.Applies(account) {
+			ruleSets = append(ruleSets, ruleSet)
+		}
+	}
+	return ruleSets
+}
+
+// NeedsPeers reports whether checking account needs the passphrases of other
+// accounts, fetching them is expensive so callers only do it when required
+func (policy *Policy) NeedsPeers(account Account) bool {
+	for _, ruleSet := range policy.RuleSetsFor(account) {
+		if ruleSet.NoReuse {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxAge returns the shortest max_age of the rule sets that apply to account
+// and the name of that rule set, zero when none sets one
+func (policy *Policy) MaxAge(account Account) (time.Duration, string) {
+	var shortest time.Duration
+	var name string
+	for _, ruleSet := range policy.RuleSetsFor(account) {
+		if ruleSet.maxAge > 0 && (shortest == 0 || ruleSet.maxAge < shortest) {
+			shortest, name = ruleSet.maxAge, ruleSet.Name
+		}
+	}
+	return shortest, name
+}
+
+// ParseAge reads ages in days, weeks or years like 90d, 12w or 1y, and Go
+// durations like 36h
+func ParseAge(text string) (time.Duration, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, fmt.Errorf("age is empty")
+	}
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour, 'y': 365 * 24 * time.Hour}
+	if unit, ok := units[text[len(text)-1]]; ok && len(text) > 1 {
+		count, err := strconv.Atoi(text[:len(text)-1])
+		if err != nil || count <= 0 {
+			return 0, fmt.Errorf("%q is not a positive number of days, weeks or years", text)
+		}
+		return time.Duration(count) * unit, nil
+	}
+
+	age, err := time.ParseDuration(text)
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("%q is not an age like 90d, 12w or 1y", text)
+	}
+	return age, nil
+}
+
+// Check returns the violations of passphrase for account, peers are only
+// used by no_reuse and may be nil when NeedsPeers is false
+func (policy *Policy) Check(account Account, passphrase string, peers []Peer) []Violation {
+	var violations []Violation
+	for _, ruleSet := range policy.RuleSetsFor(account) {
+		for _, message := range ruleSet.check(account, passphrase, peers) {
+			violations = append(violations, Violation{RuleSet: ruleSet.Name, Message: message})
+		}
+	}
+	return violations
+}
+
+func (ruleSet RuleSet) check(account Account, passphrase string, peers []Peer) []string {
+	var messages []string
+	length := len([]rune(passphrase))
+
+	if ruleSet.MinLength > 0 && length < ruleSet.MinLength {
+		messages = append(messages, fmt.Sprintf("passphrase has %d characters, at least %d are required", length, ruleSet.MinLength))
+	}
+	if ruleSet.MaxLength > 0 && length > ruleSet.MaxLength {
+		messages = append(messages, fmt.Sprintf("passphrase has %d characters, at most %d are allowed", length, ruleSet.MaxLength))
+	}
+
+	for _, class := range ruleSet.Require {
+		if !strings.ContainsFunc(passphrase, classes[class]) {
+			messages = append(messages, "passphrase needs at least one "+class+" character")
+		}
+	}
+
+	if ruleSet.MinStrength > 0 {
+		result := strength.Check(passphrase, account.Platform, account.Identifier)
+		if result.Score < ruleSet.MinStrength {
+			message := fmt.Sprintf("passphrase strength is %d, at least %d is required", result.Score, ruleSet.MinStrength)
+			if result.Warning != "" {
+				message += " (" + result.Warning + ")"
+			}
+			messages = append(messages, message)
+		}
+	}
+
+	if ruleSet.NoReuse {
+		for _, peer := range peers {
+			if peer.Account.ID == account.ID && account.ID != "" {
+				continue
+			}
+			if peer.Passphrase == passphrase && ruleSet.Applies(peer.Account) {
+				messages = append(messages, "passphrase is already used by "+peer.Account.Platform+" ("+peer.Account.Identifier+")")
+			}
+		}
+	}
+	return messages
+}
@@ -0,0 +1,193 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLeftParen
+	tokenRightParen
+)
+
+func (kind tokenKind) String() string {
+	switch kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenIdentifier:
+		return "field name"
+	case tokenString:
+		return "string"
+	case tokenNumber:
+		return "number"
+	case tokenOperator:
+		return "operator"
+	case tokenAnd:
+		return "'and'"
+	case tokenOr:
+		return "'or'"
+	case tokenNot:
+		return "'not'"
+	case tokenLeftParen:
+		return "'('"
+	case tokenRightParen:
+		return "')'"
+	}
+	return "token"
+}
+
+type token struct {
+	kind     tokenKind
+	text     string // Raw text for operators and identifiers, decoded value for strings
+	position int    // Zero based rune offset in the source
+}
+
+// operators are ordered so two character operators are tried first
+var operators = []string{"!=", "!~", "<=", ">=", "==", "=", "~", "<", ">"}
+
+type lexer struct {
+	source []rune
+	offset int
+}
+
+func tokenize(source string) ([]token, error) {
+	lexer := &lexer{source: []rune(source)}
+	var tokens []token
+
+	for {
+		next, err := lexer.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, next)
+		if next.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (lexer *lexer) next() (token, error) {
+	for lexer.offset < len(lexer.source) && unicode.IsSpace(lexer.source[lexer.offset]) {
+		lexer.offset++
+	}
+
+	start := lexer.offset
+	if start >= len(lexer.source) {
+		return token{kind: tokenEOF, position: start}, nil
+	}
+
+	character := lexer.source[start]
+	switch {
+	case character == '(':
+		lexer.offset++
+		return token{kind: tokenLeftParen, text: "(", position: start}, nil
+	case character == ')':
+		lexer.offset++
+		return token{kind: tokenRightParen, text: ")", position: start}, nil
+	case character == '"' || character == '\'':
+		return lexer.readString(character)
+	case isDigit(character) || (character == '-' && lexer.peekDigit()):
+		lexer.offset++
+		for lexer.offset < len(lexer.source) && isDigit(lexer.source[lexer.offset]) {
+			lexer.offset++
+		}
+		return token{kind: tokenNumber, text: string(lexer.source[start:lexer.offset]), position: start}, nil
+	case unicode.IsLetter(character) || character == '_':
+		for lexer.offset < len(lexer.source) &&
+			(unicode.IsLetter(lexer.source[lexer.offset]) ||
+				isDigit(lexer.source[lexer.offset]) ||
+				lexer.source[lexer.offset] == '_') {
+			lexer.offset++
+		}
+		word := string(lexer.source[start:lexer.offset])
+		switch strings.ToLower(word) {
+		case "and":
+			return token{kind: tokenAnd, text: word, position: start}, nil
+		case "or":
+			return token{kind: tokenOr, text: word, position: start}, nil
+		case "not":
+			return token{kind: tokenNot, text: word, position: start}, nil
+		}
+		return token{kind: tokenIdentifier, text: word, position: start}, nil
+	}
+
+	// Symbolic spellings of the boolean keywords
+	rest := string(lexer.source[start:])
+	if strings.HasPrefix(rest, "&&") {
+		lexer.offset += 2
+		return token{kind: tokenAnd, text: "&&", position: start}, nil
+	}
+	if strings.HasPrefix(rest, "||") {
+		lexer.offset += 2
+		return token{kind: tokenOr, text: "||", position: start}, nil
+	}
+	for _, operator := range operators {
+		if strings.HasPrefix(rest, operator) {
+			lexer.offset += len([]rune(operator))
+			return token{kind: tokenOperator, text: operator, position: start}, nil
+		}
+	}
+	if character == '!' {
+		lexer.offset++
+		return token{kind: tokenNot, text: "!", position: start}, nil
+	}
+
+	return token{}, &Error{Position: start, Message: "unexpected character '" + string(character) + "'"}
+}
+
+func (lexer *lexer) peekDigit() bool {
+	return lexer.offset+1 < len(lexer.source) && isDigit(lexer.source[lexer.offset+1])
+}
+
+func (lexer *lexer) readString(quote rune) (token, error) {
+	start := lexer.offset
+	lexer.offset++ // Opening quote
+
+	var value strings.Builder
+	for lexer.offset < len(lexer.source) {
+		character := lexer.source[lexer.offset]
+		lexer.offset++
+
+		switch character {
+		case quote:
+			return token{kind: tokenString, text: value.String(), position: start}, nil
+		case '\\':
+			if lexer.offset >= len(lexer.source) {
+				return token{}, &Error{Position: lexer.offset - 1, Message: "unfinished escape sequence"}
+			}
+			escaped := lexer.source[lexer.offset]
+			lexer.offset++
+			switch escaped {
+			case 'n':
+				value.WriteRune('\n')
+			case 't':
+				value.WriteRune('\t')
+			case quote, '\\':
+				value.WriteRune(escaped)
+			default:
+				// Keep unknown escapes so regular expressions like \d survive
+				value.WriteRune('\\')
+				value.WriteRune(escaped)
+			}
+		default:
+			value.WriteRune(character)
+		}
+	}
+
+	return token{}, &Error{Position: start, Message: "string is not closed"}
+}
+
+// isDigit accepts only ASCII digits, strconv.Atoi rejects the others
+func isDigit(character rune) bool {
+	return character >= '0' && character <= '9'
+}
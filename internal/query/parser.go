@@ -0,0 +1,192 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Error points at the offending position of a query so it can be shown to the user
+type Error struct {
+	Position int
+	Message  string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", err.Position+1, err.Message)
+}
+
+// Explain renders the query with a caret under the error position
+func (err *Error) Explain(source string) string {
+	return fmt.Sprintf(
+		"%s\n%s^\n%s",
+		source,
+		strings.Repeat(" ", err.Position),
+		err.Error(),
+	)
+}
+
+type parser struct {
+	tokens []token
+	index  int
+}
+
+func (parser *parser) peek() token {
+	return parser.tokens[parser.index]
+}
+
+func (parser *parser) advance() token {
+	current := parser.tokens[parser.index]
+	if current.kind != tokenEOF {
+		parser.index++
+	}
+	return current
+}
+
+func (parser *parser) expect(kind tokenKind) (token, error) {
+	current := parser.peek()
+	if current.kind != kind {
+		return current, unexpected(current, "expected "+kind.String())
+	}
+	return parser.advance(), nil
+}
+
+func unexpected(found token, message string) *Error {
+	description := found.kind.String()
+	if found.kind != tokenEOF {
+		description += " '" + found.text + "'"
+		if found.kind == tokenString {
+			description = "string \"" + found.text + "\""
+		}
+	}
+	return &Error{Position: found.position, Message: message + ", found " + description}
+}
+
+// expression := term ("or" term)*
+func (parser *parser) parseExpression() (node, error) {
+	left, err := parser.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for parser.peek().kind == tokenOr {
+		parser.advance()
+		right, err := parser.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// term := factor ("and" factor)*
+func (parser *parser) parseTerm() (node, error) {
+	left, err := parser.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for parser.peek().kind == tokenAnd {
+		parser.advance()
+		right, err := parser.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// factor := "not" factor | "(" expression ")" | comparison
+func (parser *parser) parseFactor() (node, error) {
+	switch parser.peek().kind {
+	case tokenNot:
+		parser.advance()
+		operand, err := parser.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	case tokenLeftParen:
+		open := parser.advance()
+		inner, err := parser.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if parser.peek().kind != tokenRightParen {
+			return nil, unexpected(parser.peek(), fmt.Sprintf("expected ')' to close '(' at column %d", open.position+1))
+		}
+		parser.advance()
+		return inner, nil
+	}
+	return parser.parseComparison()
+}
+
+// comparison := field operator literal
+func (parser *parser) parseComparison() (node, error) {
+	fieldToken := parser.peek()
+	if fieldToken.kind != tokenIdentifier {
+		return nil, unexpected(fieldToken, "expected a field name ("+strings.Join(fieldNames(), ", ")+")")
+	}
+	parser.advance()
+
+	field, ok := fields[strings.ToLower(fieldToken.text)]
+	if !ok {
+		return nil, &Error{
+			Position: fieldToken.position,
+			Message:  "unknown field '" + fieldToken.text + "', expected one of " + strings.Join(fieldNames(), ", "),
+		}
+	}
+
+	operatorToken, err := parser.expect(tokenOperator)
+	if err != nil {
+		return nil, err
+	}
+	operator := operatorToken.text
+	if operator == "==" {
+		operator = "="
+	}
+
+	valueToken := parser.advance()
+	comparison := &comparisonNode{field: field, operator: operator}
+
+	// Type checking: the literal has to agree with the field and the operator
+	switch field.kind {
+	case kindString:
+		if valueToken.kind != tokenString {
+			return nil, unexpected(valueToken, "field '"+field.name+"' is text, expected a quoted string")
+		}
+		switch operator {
+		case "=", "!=":
+			comparison.text = valueToken.text
+		case "~", "!~":
+			pattern, err := regexp.Compile("(?i)" + valueToken.text)
+			if err != nil {
+				return nil, &Error{Position: valueToken.position, Message: "invalid pattern: " + err.Error()}
+			}
+			comparison.pattern = pattern
+		default:
+			return nil, &Error{
+				Position: operatorToken.position,
+				Message:  "operator '" + operator + "' cannot be used with text field '" + field.name + "', use =, !=, ~ or !~",
+			}
+		}
+	case kindNumber:
+		if operator == "~" || operator == "!~" {
+			return nil, &Error{
+				Position: operatorToken.position,
+				Message:  "operator '" + operator + "' cannot be used with number field '" + field.name + "'",
+			}
+		}
+		if valueToken.kind != tokenNumber {
+			return nil, unexpected(valueToken, "field '"+field.name+"' is a number, expected a number")
+		}
+		number, err := strconv.Atoi(valueToken.text)
+		if err != nil {
+			return nil, &Error{Position: valueToken.position, Message: "number is out of range"}
+		}
+		comparison.number = number
+	}
+
+	return comparison, nil
+}
@@ -0,0 +1,165 @@
+package query
+
+import (
+	"regexp"
+	"sort"
+
+	"passenger-go-cli/internal/schemas"
+)
+
+/**
+ * Query syntax:
+ * - Comparisons: <field> <operator> <value>
+ *   e.g. platform ~ "github", strength < 60, url = ""
+ * - Text fields: id, platform, identifier, url, notes
+ *   operators: = and != (exact), ~ and !~ (case-insensitive regular expression)
+ * - Number fields: strength
+ *   operators: =, !=, <, <=, >, >=
+ * - Combine with and, or, not (or &&, ||, !) and parentheses
+ */
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+)
+
+type field struct {
+	name   string
+	kind   valueKind
+	text   func(schemas.Account) string
+	number func(schemas.Account) int
+}
+
+var fields = map[string]field{
+	"id":         {name: "id", kind: kindString, text: func(account schemas.Account) string { return account.ID }},
+	"platform":   {name: "platform", kind: kindString, text: func(account schemas.Account) string { return account.Platform }},
+	"identifier": {name: "identifier", kind: kindString, text: func(account schemas.Account) string { return account.Identifier }},
+	"url":        {name: "url", kind: kindString, text: func(account schemas.Account) string { return account.URL }},
+	"notes":      {name: "notes", kind: kindString, text: func(account schemas.Account) string { return account.Notes }},
+	"strength":   {name: "strength", kind: kindNumber, number: func(account schemas.Account) int { return account.Strength }},
+}
+
+func fieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type node interface {
+	evaluate(account schemas.Account) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n *andNode) evaluate(account schemas.Account) bool {
+	return n.left.evaluate(account) && n.right.evaluate(account)
+}
+
+type orNode struct{ left, right node }
+
+func (n *orNode) evaluate(account schemas.Account) bool {
+	return n.left.evaluate(account) || n.right.evaluate(account)
+}
+
+type notNode struct{ operand node }
+
+func (n *notNode) evaluate(account schemas.Account) bool {
+	return !n.operand.evaluate(account)
+}
+
+type comparisonNode struct {
+	field    field
+	operator string
+	text     string
+	number   int
+	pattern  *regexp.Regexp
+}
+
+func (n *comparisonNode) evaluate(account schemas.Account) bool {
+	if n.field.kind == kindString {
+		value := n.field.text(account)
+		switch n.operator {
+		case "=":
+			return value == n.text
+		case "!=":
+			return value != n.text
+		case "~":
+			return n.pattern.MatchString(value)
+		case "!~":
+			return !n.pattern.MatchString(value)
+		}
+		return false
+	}
+
+	value := n.field.number(account)
+	switch n.operator {
+	case "=":
+		return value == n.number
+	case "!=":
+		return value != n.number
+	case "<":
+		return value < n.number
+	case "<=":
+		return value <= n.number
+	case ">":
+		return value > n.number
+	case ">=":
+		return value >= n.number
+	}
+	return false
+}
+
+// Query is a compiled filter expression
+type Query struct {
+	source string
+	root   node
+}
+
+// Compile parses and type checks a query. Errors are of type *Error.
+func Compile(source string) (*Query, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &parser{tokens: tokens}
+	if parser.peek().kind == tokenEOF {
+		return nil, &Error{Position: 0, Message: "query is empty"}
+	}
+
+	root, err := parser.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if parser.peek().kind != tokenEOF {
+		return nil, unexpected(parser.peek(), "expected 'and', 'or' or end of query")
+	}
+
+	return &Query{source: source, root: root}, nil
+}
+
+// String returns the source the query was compiled from
+func (query *Query) String() string {
+	return query.source
+}
+
+// Match reports whether the account satisfies the query
+func (query *Query) Match(account schemas.Account) bool {
+	return query.root.evaluate(account)
+}
+
+// Filter returns the accounts that satisfy the query, keeping their order
+func (query *Query) Filter(accounts []schemas.Account) []schemas.Account {
+	filtered := make([]schemas.Account, 0, len(accounts))
+	for _, account := range accounts {
+		if query.Match(account) {
+			filtered = append(filtered, account)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,130 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"passenger-go-cli/internal/schemas"
+)
+
+var accounts = []schemas.Account{
+	{ID: "a1", Platform: "GitHub", Identifier: "jane", URL: "https://github.com", Strength: 80},
+	{ID: "b2", Platform: "GitLab", Identifier: "jane", Strength: 40},
+	{ID: "c3", Platform: "Bank", Identifier: "jane.doe", Notes: "PIN in the safe", Strength: 20},
+	{ID: "d4", Platform: "Mail", Identifier: "doe", URL: "https://mail.example.com", Strength: 100},
+}
+
+func matchingIDs(t *testing.T, source string) string {
+	t.Helper()
+	query, err := Compile(source)
+	if err != nil {
+		t.Fatalf("Compile(%q) failed: %v", source, err)
+	}
+	var ids []string
+	for _, account := range query.Filter(accounts) {
+		ids = append(ids, account.ID)
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string // IDs of the matching accounts
+	}{
+		{`platform = "GitHub"`, "a1"},
+		{`platform == "github"`, ""},
+		{`platform != "GitHub"`, "b2 c3 d4"},
+		{`platform ~ "^git"`, "a1 b2"},
+		{`platform !~ "git"`, "c3 d4"},
+		{`url = ""`, "b2 c3"},
+		{`notes ~ "pin"`, "c3"},
+		{`identifier ~ 'jane\.doe'`, "c3"},
+		{`strength < 40`, "c3"},
+		{`strength <= 40`, "b2 c3"},
+		{`strength > 80`, "d4"},
+		{`strength >= 80`, "a1 d4"},
+		{`strength = -1`, ""},
+		{`strength != 100`, "a1 b2 c3"},
+		{`ID = "a1"`, "a1"},
+
+		// Precedence: not binds tightest, then and, then or
+		{`platform = "Bank" or platform ~ "git" and strength > 50`, "a1 c3"},
+		{`(platform = "Bank" or platform ~ "git") and strength > 50`, "a1"},
+		{`platform ~ "git" and strength > 50 or platform = "Bank"`, "a1 c3"},
+		{`not platform ~ "git" and strength < 50`, "c3"},
+		{`not (platform ~ "git" and strength < 50)`, "a1 c3 d4"},
+		{`not not strength = 100`, "d4"},
+		{`platform ~ "git" && !(strength > 50) || url ~ "mail"`, "b2 d4"},
+		{`strength>50AND identifier="jane"`, "a1"},
+	}
+
+	for _, test := range tests {
+		if got := matchingIDs(t, test.source); got != test.want {
+			t.Errorf("%s matched %q, want %q", test.source, got, test.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		source   string
+		position int
+		message  string
+	}{
+		{``, 0, "query is empty"},
+		{`   `, 0, "query is empty"},
+		{`owner = "me"`, 0, "unknown field 'owner'"},
+		{`platform "GitHub"`, 9, "expected operator"},
+		{`platform =`, 10, "expected a quoted string, found end of query"},
+		{`platform = GitHub`, 11, "is text, expected a quoted string"},
+		{`platform < "G"`, 9, "operator '<' cannot be used with text field"},
+		{`strength ~ "9"`, 9, "operator '~' cannot be used with number field"},
+		{`strength > "50"`, 11, "is a number, expected a number"},
+		{`strength > 99999999999999999999`, 11, "out of range"},
+		{`strength > ٥٠`, 11, "unexpected character '٥'"},
+		{`strength > 5٠`, 12, "unexpected character '٠'"},
+		{`platform ~ "("`, 11, "invalid pattern"},
+		{`platform = "GitHub`, 11, "string is not closed"},
+		{`platform = "GitHub\`, 18, "unfinished escape sequence"},
+		{`platform = "a" and`, 18, "expected a field name"},
+		{`platform = "a" strength = 1`, 15, "expected 'and', 'or' or end of query"},
+		{`(platform = "a" or strength = 1`, 31, "expected ')' to close '(' at column 1"},
+		{`platform = "a")`, 14, "expected 'and', 'or' or end of query"},
+		{`platform = "a" & strength = 1`, 15, "unexpected character '&'"},
+		{`plätform = "a"`, 0, "unknown field 'plätform'"},
+		{`strength = 1 and plätform = "a"`, 17, "unknown field"},
+	}
+
+	for _, test := range tests {
+		_, err := Compile(test.source)
+		var queryError *Error
+		if !errors.As(err, &queryError) {
+			t.Errorf("Compile(%q) = %v, want a query error", test.source, err)
+			continue
+		}
+		if queryError.Position != test.position || !strings.Contains(queryError.Message, test.message) {
+			t.Errorf("Compile(%q) = %q at %d, want %q at %d",
+				test.source, queryError.Message, queryError.Position, test.message, test.position)
+		}
+	}
+}
+
+func TestErrorExplain(t *testing.T) {
+	source := `notes = "café" and owner = "a"`
+	_, err := Compile(source)
+	var queryError *Error
+	if !errors.As(err, &queryError) {
+		t.Fatalf("Compile(%q) = %v, want a query error", source, err)
+	}
+
+	// The caret counts runes, so the accent before the field does not shift it
+	lines := strings.Split(queryError.Explain(source), "\n")
+	if len(lines) != 3 || lines[0] != source || lines[1] != strings.Repeat(" ", 19)+"^" {
+		t.Errorf("Explain() = %q", lines)
+	}
+	if !strings.HasPrefix(lines[2], "column 20: ") {
+		t.Errorf("Explain() ends with %q, want column 20", lines[2])
+	}
+}
@@ -0,0 +1,132 @@
+package resolver
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/schemas"
+)
+
+// AmbiguousError is returned when a reference matches more than one account
+type AmbiguousError struct {
+	Reference  string
+	Candidates []schemas.Account
+}
+
+func (err *AmbiguousError) Error() string {
+	return fmt.Sprintf("%q matches %d accounts", err.Reference, len(err.Candidates))
+}
+
+// NotFoundError is returned when a reference matches no account
+type NotFoundError struct {
+	Reference string
+}
+
+func (err *NotFoundError) Error() string {
+	return fmt.Sprintf("no account matches %q", err.Reference)
+}
+
+// Resolve finds the account a user means by reference. It accepts, in order
+// of precedence: a full ID, platform/identifier, a platform name, a unique ID
+// prefix of at least four hex characters and a URL host. The first rule that
+// matches anything wins, so a short ID prefix never shadows a platform.
+func Resolve(reference string) (*schemas.Account, error) {
+	accounts, err := api.GetAccounts()
+	if err != nil {
+		return nil, err
+	}
+	return ResolveIn(reference, accounts)
+}
+
+// ResolveIn is like Resolve but searches an already fetched list of accounts
+func ResolveIn(reference string, accounts []schemas.Account) (*schemas.Account, error) {
+	reference = strings.TrimSpace(reference)
+	if reference == "" {
+		return nil, &NotFoundError{Reference: reference}
+	}
+
+	rules := []func(string, schemas.Account) bool{
+		matchID,
+		matchPlatformIdentifier,
+		matchPlatform,
+		matchIDPrefix,
+		matchHost,
+	}
+
+	for _, rule := range rules {
+		var candidates []schemas.Account
+		for _, account := range accounts {
+			if rule(reference, account) {
+				candidates = append(candidates, account)
+			}
+		}
+
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			return &candidates[0], nil
+		default:
+			return nil, &AmbiguousError{Reference: reference, Candidates: candidates}
+		}
+	}
+
+	return nil, &NotFoundError{Reference: reference}
+}
+
+func matchID(reference string, account schemas.Account) bool {
+	return account.ID == reference
+}
+
+// minIDPrefix is how many hex characters an ID prefix needs, shorter ones
+// match too many accounts by accident
+const minIDPrefix = 4
+
+func matchIDPrefix(reference string, account schemas.Account) bool {
+	hex := 0
+	for _, character := range strings.ToLower(reference) {
+		switch {
+		case character >= '0' && character <= '9', character >= 'a' && character <= 'f':
+			hex++
+		case character != '-':
+			return false
+		}
+	}
+	return hex >= minIDPrefix && strings.HasPrefix(strings.ToLower(account.ID), strings.ToLower(reference))
+}
+
+func matchPlatformIdentifier(reference string, account schemas.Account) bool {
+	platform, identifier, found := strings.Cut(reference, "/")
+	return found &&
+		strings.EqualFold(account.Platform, platform) &&
+		strings.EqualFold(account.Identifier, identifier)
+}
+
+func matchPlatform(reference string, account schemas.Account) bool {
+	return strings.EqualFold(account.Platform, reference)
+}
+
+func matchHost(reference string, account schemas.Account) bool {
+	host := Host(reference)
+	return host != "" && host == Host(account.URL)
+}
+
+// Host extracts the lowercase host of a URL without a leading www., the
+// scheme is optional so "github.com/login" works as well
+func Host(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || !strings.Contains(parsed.Hostname(), ".") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
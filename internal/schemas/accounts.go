@@ -22,3 +22,8 @@ type UpsertAccountRequest struct {
 	Notes      string `json:"notes"`
 	Passphrase string `json:"passphrase"`
 }
+
+type AccountPassphrase struct {
+	ID         string `json:"id"`
+	Passphrase string `json:"passphrase"`
+}
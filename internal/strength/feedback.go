@@ -0,0 +1,102 @@
+package strength
+
+import (
+	"strings"
+	"unicode"
+)
+
+// feedbackBelowScore is the score from which no feedback is given
+const feedbackBelowScore = 70
+
+// feedback explains what makes a passphrase weak, based on its longest match
+func feedback(score int, sequence []Match) (string, []string) {
+	if len(sequence) == 0 {
+		return "", []string{
+			"Use a few words, avoid common phrases",
+			"No need for symbols, digits, or uppercase letters",
+		}
+	}
+	if score >= feedbackBelowScore {
+		return "", nil
+	}
+
+	longest := sequence[0]
+	for _, match := range sequence[1:] {
+		if match.length() > longest.length() {
+			longest = match
+		}
+	}
+
+	warning, suggestions := matchFeedback(longest, len(sequence) == 1)
+	return warning, append([]string{"Add another word or two, uncommon words are better"}, suggestions...)
+}
+
+func matchFeedback(match Match, onlyMatch bool) (string, []string) {
+	switch match.Pattern {
+	case PatternDictionary:
+		return dictionaryFeedback(match, onlyMatch)
+	case PatternSpatial:
+		warning := "Short keyboard patterns are easy to guess"
+		if match.Turns == 1 {
+			warning = "Straight rows of keys are easy to guess"
+		}
+		return warning, []string{"Use a longer keyboard pattern with more turns"}
+	case PatternRepeat:
+		warning := "Repeats like \"abcabcabc\" are only slightly harder to guess than \"abc\""
+		if len([]rune(match.BaseToken)) == 1 {
+			warning = "Repeats like \"aaa\" are easy to guess"
+		}
+		return warning, []string{"Avoid repeated words and characters"}
+	case PatternSequence:
+		return "Sequences like abc or 6543 are easy to guess", []string{"Avoid sequences"}
+	case PatternYear:
+		return "Recent years are easy to guess", []string{"Avoid recent years", "Avoid years that are associated with you"}
+	case PatternDate:
+		return "Dates are often easy to guess", []string{"Avoid dates and years that are associated with you"}
+	}
+	return "", nil
+}
+
+func dictionaryFeedback(match Match, onlyMatch bool) (string, []string) {
+	var warning string
+	switch match.Dictionary {
+	case "passwords":
+		switch {
+		case onlyMatch && len(match.Substitutions) == 0 && !match.Reversed && match.Rank <= 10:
+			warning = "This is a top-10 common password"
+		case onlyMatch && len(match.Substitutions) == 0 && !match.Reversed && match.Rank <= 100:
+			warning = "This is a top-100 common password"
+		case onlyMatch && len(match.Substitutions) == 0 && !match.Reversed:
+			warning = "This is a very common password"
+		default:
+			warning = "This is similar to a commonly used password"
+		}
+	case "english":
+		if onlyMatch {
+			warning = "A word by itself is easy to guess"
+		}
+	case "names", "surnames":
+		warning = "Common names and surnames are easy to guess"
+		if onlyMatch {
+			warning = "Names and surnames by themselves are easy to guess"
+		}
+	case "user inputs":
+		warning = "Details of the account itself are easy to guess"
+	}
+
+	var suggestions []string
+	runes := []rune(match.Token)
+	switch {
+	case unicode.IsUpper(runes[0]) && strings.ToLower(string(runes[1:])) == string(runes[1:]):
+		suggestions = append(suggestions, "Capitalization doesn't help very much")
+	case strings.ToUpper(match.Token) == match.Token && strings.ToLower(match.Token) != match.Token:
+		suggestions = append(suggestions, "All-uppercase is almost as easy to guess as all-lowercase")
+	}
+	if match.Reversed && len(runes) >= 4 {
+		suggestions = append(suggestions, "Reversed words aren't much harder to guess")
+	}
+	if len(match.Substitutions) > 0 {
+		suggestions = append(suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much")
+	}
+	return warning, suggestions
+}
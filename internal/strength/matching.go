@@ -0,0 +1,290 @@
+package strength
+
+import (
+	_ "embed"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Patterns a match can have
+const (
+	PatternDictionary = "dictionary"
+	PatternSpatial    = "spatial"
+	PatternRepeat     = "repeat"
+	PatternSequence   = "sequence"
+	PatternDate       = "date"
+	PatternYear       = "year"
+	PatternBruteforce = "bruteforce"
+)
+
+// Match is a part of the passphrase that follows a guessable pattern
+type Match struct {
+	Pattern string
+	I, J    int // First and last rune index, inclusive
+	Token   string
+
+	// Dictionary matches
+	Dictionary    string
+	Word          string // The dictionary word, lowercase and without substitutions
+	Rank          int    // 1 for the most common word
+	Reversed      bool
+	Substitutions map[rune]rune // l33t character to the letter it replaces
+
+	// Spatial matches
+	Graph   string
+	Turns   int
+	Shifted int
+
+	// Repeat matches
+	BaseToken   string
+	BaseGuesses float64
+	Repeats     int
+
+	// Sequence matches
+	Ascending bool
+
+	// Date and year matches
+	Year      int
+	Separator string
+
+	Guesses float64
+}
+
+func (match Match) length() int {
+	return match.J - match.I + 1
+}
+
+//go:embed lists/passwords.txt
+var passwordsList string
+
+//go:embed lists/english.txt
+var englishList string
+
+//go:embed lists/names.txt
+var namesList string
+
+//go:embed lists/surnames.txt
+var surnamesList string
+
+// rankedDictionaries maps each dictionary name to word ranks, built on first use
+var rankedDictionaries = sync.OnceValue(func() map[string]map[string]int {
+	return map[string]map[string]int{
+		"passwords": rank(strings.Split(passwordsList, "\n")),
+		"english":   rank(strings.Split(englishList, "\n")),
+		"names":     rank(strings.Split(namesList, "\n")),
+		"surnames":  rank(strings.Split(surnamesList, "\n")),
+	}
+})
+
+func rank(words []string) map[string]int {
+	ranks := make(map[string]int, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if _, seen := ranks[word]; word != "" && !seen {
+			ranks[word] = len(ranks) + 1
+		}
+	}
+	return ranks
+}
+
+// omnimatch finds every pattern in the passphrase, sorted by position
+func omnimatch(passphrase string, userInputs []string) []Match {
+	dictionaries := rankedDictionaries()
+	if len(userInputs) > 0 {
+		var words []string
+		for _, input := range userInputs {
+			// Parts of an identifier like "jane.doe@example.com" count separately
+			words = append(words, strings.ToLower(input))
+			words = append(words, strings.FieldsFunc(strings.ToLower(input), func(character rune) bool {
+				return !unicode.IsLetter(character) && !unicode.IsDigit(character)
+			})...)
+		}
+		dictionaries = mapsWith(dictionaries, "user inputs", rank(words))
+	}
+
+	runes := []rune(passphrase)
+	var matches []Match
+	matches = append(matches, dictionaryMatches(runes, dictionaries)...)
+	matches = append(matches, reverseDictionaryMatches(runes, dictionaries)...)
+	matches = append(matches, l33tMatches(runes, dictionaries)...)
+	matches = append(matches, spatialMatches(runes)...)
+	matches = append(matches, repeatMatches(runes, userInputs)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, yearMatches(runes)...)
+	matches = append(matches, dateMatches(runes)...)
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		if a.I != b.I {
+			return a.I - b.I
+		}
+		return a.J - b.J
+	})
+	return matches
+}
+
+func mapsWith(dictionaries map[string]map[string]int, name string, ranks map[string]int) map[string]map[string]int {
+	extended := make(map[string]map[string]int, len(dictionaries)+1)
+	for key, value := range dictionaries {
+		extended[key] = value
+	}
+	extended[name] = ranks
+	return extended
+}
+
+// dictionaryMatches finds every substring that is a dictionary word
+func dictionaryMatches(runes []rune, dictionaries map[string]map[string]int) []Match {
+	lower := []rune(strings.ToLower(string(runes)))
+	if len(lower) != len(runes) {
+		lower = runes // Lowercasing changed the length, compare as is
+	}
+
+	var matches []Match
+	for i := range lower {
+		for j := i; j < len(lower); j++ {
+			word := string(lower[i : j+1])
+			for name, ranks := range dictionaries {
+				if rank, ok := ranks[word]; ok {
+					matches = append(matches, Match{
+						Pattern:    PatternDictionary,
+						I:          i,
+						J:          j,
+						Token:      string(runes[i : j+1]),
+						Dictionary: name,
+						Word:       word,
+						Rank:       rank,
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// reverseDictionaryMatches finds words written backwards
+func reverseDictionaryMatches(runes []rune, dictionaries map[string]map[string]int) []Match {
+	reversed := slices.Clone(runes)
+	slices.Reverse(reversed)
+
+	var matches []Match
+	for _, match := range dictionaryMatches(reversed, dictionaries) {
+		match.Token = reverseString(match.Token)
+		match.Reversed = true
+		match.I, match.J = len(runes)-1-match.J, len(runes)-1-match.I
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+func reverseString(text string) string {
+	runes := []rune(text)
+	slices.Reverse(runes)
+	return string(runes)
+}
+
+// l33tTable lists the letters a character may stand for
+var l33tTable = map[rune][]rune{
+	'4': {'a'}, '@': {'a'},
+	'8': {'b'},
+	'(': {'c'}, '{': {'c'}, '[': {'c'}, '<': {'c'},
+	'3': {'e'},
+	'6': {'g'}, '9': {'g'},
+	'1': {'i', 'l'}, '!': {'i'}, '|': {'i', 'l'},
+	'7': {'l', 't'},
+	'0': {'o'},
+	'$': {'s'}, '5': {'s'},
+	'+': {'t'},
+	'%': {'x'},
+	'2': {'z'},
+}
+
+// maxL33tVariants bounds how many ways one substring is read
+const maxL33tVariants = 64
+
+// l33tMatches finds dictionary words with letters replaced by look-alikes
+func l33tMatches(runes []rune, dictionaries map[string]map[string]int) []Match {
+	lower := []rune(strings.ToLower(string(runes)))
+	if len(lower) != len(runes) {
+		return nil
+	}
+
+	var matches []Match
+	for i := range lower {
+		for j := i + 1; j < len(lower); j++ {
+			token := lower[i : j+1]
+			if !slices.ContainsFunc(token, func(character rune) bool { return l33tTable[character] != nil }) {
+				continue
+			}
+
+			for _, variant := range unl33t(token) {
+				word := string(variant.word)
+				for name, ranks := range dictionaries {
+					rank, ok := ranks[word]
+					if !ok {
+						continue
+					}
+					matches = append(matches, Match{
+						Pattern:       PatternDictionary,
+						I:             i,
+						J:             j,
+						Token:         string(runes[i : j+1]),
+						Dictionary:    name,
+						Word:          word,
+						Rank:          rank,
+						Substitutions: variant.substitutions,
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+type l33tVariant struct {
+	word          []rune
+	substitutions map[rune]rune
+}
+
+// unl33t returns the readings of token with l33t characters replaced by
+// letters. A character is read the same way everywhere in the token.
+func unl33t(token []rune) []l33tVariant {
+	var l33tCharacters []rune
+	for _, character := range token {
+		if l33tTable[character] != nil && !slices.Contains(l33tCharacters, character) {
+			l33tCharacters = append(l33tCharacters, character)
+		}
+	}
+
+	substitutions := []map[rune]rune{{}}
+	for _, character := range l33tCharacters {
+		var next []map[rune]rune
+		for _, existing := range substitutions {
+			for _, letter := range l33tTable[character] {
+				extended := map[rune]rune{character: letter}
+				for key, value := range existing {
+					extended[key] = value
+				}
+				next = append(next, extended)
+			}
+		}
+		substitutions = next
+		if len(substitutions) > maxL33tVariants {
+			substitutions = substitutions[:maxL33tVariants]
+		}
+	}
+
+	variants := make([]l33tVariant, 0, len(substitutions))
+	for _, substitution := range substitutions {
+		word := make([]rune, len(token))
+		for index, character := range token {
+			if letter, ok := substitution[character]; ok {
+				word[index] = letter
+			} else {
+				word[index] = character
+			}
+		}
+		variants = append(variants, l33tVariant{word: word, substitutions: substitution})
+	}
+	return variants
+}
@@ -0,0 +1,228 @@
+package strength
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// repeatMatches finds a block repeated right after itself, like "aaa" or "abcabc"
+func repeatMatches(runes []rune, userInputs []string) []Match {
+	var matches []Match
+	i := 0
+	for i < len(runes)-1 {
+		bestBase, bestRepeats := 0, 0
+		for base := 1; base <= (len(runes)-i)/2; base++ {
+			repeats := 1
+			for i+(repeats+1)*base <= len(runes) &&
+				string(runes[i+repeats*base:i+(repeats+1)*base]) == string(runes[i:i+base]) {
+				repeats++
+			}
+			// The longest repeated stretch wins, shorter blocks break ties
+			if repeats >= 2 && base*repeats > bestBase*bestRepeats {
+				bestBase, bestRepeats = base, repeats
+			}
+		}
+
+		if bestRepeats < 2 {
+			i++
+			continue
+		}
+
+		baseToken := string(runes[i : i+bestBase])
+		baseGuesses, _ := mostGuessableSequence(baseToken, omnimatch(baseToken, userInputs))
+		length := bestBase * bestRepeats
+		matches = append(matches, Match{
+			Pattern:     PatternRepeat,
+			I:           i,
+			J:           i + length - 1,
+			Token:       string(runes[i : i+length]),
+			BaseToken:   baseToken,
+			BaseGuesses: baseGuesses,
+			Repeats:     bestRepeats,
+		})
+		i += length
+	}
+	return matches
+}
+
+// maxSequenceDelta is the largest step between characters still counted as a sequence
+const maxSequenceDelta = 5
+
+// sequenceMatches finds runs with a constant step like "abc", "2468" or "zyx"
+func sequenceMatches(runes []rune) []Match {
+	var matches []Match
+	add := func(i, j int, delta rune) {
+		if j-i < 2 || delta == 0 || delta > maxSequenceDelta || delta < -maxSequenceDelta {
+			return
+		}
+		matches = append(matches, Match{
+			Pattern:   PatternSequence,
+			I:         i,
+			J:         j,
+			Token:     string(runes[i : j+1]),
+			Ascending: delta > 0,
+		})
+	}
+
+	i := 0
+	for i < len(runes)-1 {
+		delta := runes[i+1] - runes[i]
+		j := i + 1
+		for j+1 < len(runes) && runes[j+1]-runes[j] == delta {
+			j++
+		}
+		add(i, j, delta)
+		if j-i >= 2 {
+			i = j
+		} else {
+			i++
+		}
+	}
+	return matches
+}
+
+var recentYear = regexp.MustCompile(`19\d\d|20\d\d`)
+
+// yearMatches finds years from 1900 to 2099
+func yearMatches(runes []rune) []Match {
+	var matches []Match
+	text := string(runes)
+	for _, location := range recentYear.FindAllStringIndex(text, -1) {
+		i := len([]rune(text[:location[0]]))
+		year, _ := strconv.Atoi(text[location[0]:location[1]])
+		matches = append(matches, Match{
+			Pattern: PatternYear,
+			I:       i,
+			J:       i + 3,
+			Token:   text[location[0]:location[1]],
+			Year:    year,
+		})
+	}
+	return matches
+}
+
+var separatedDate = regexp.MustCompile(`^(\d{1,4})([\s/\\_.-])(\d{1,2})([\s/\\_.-])(\d{1,4})$`)
+
+// dateMatches finds dates with or without separators, like 13.05.1987, 1987-5-13 or 130587
+func dateMatches(runes []rune) []Match {
+	var matches []Match
+
+	for i := range runes {
+		for j := i + 3; j < len(runes) && j < i+10; j++ {
+			token := string(runes[i : j+1])
+
+			if onlyDigits(token) && len(token) <= 8 {
+				if year, ok := digitDate(token); ok {
+					matches = append(matches, Match{Pattern: PatternDate, I: i, J: j, Token: token, Year: year})
+				}
+				continue
+			}
+
+			parts := separatedDate.FindStringSubmatch(token)
+			if parts == nil || parts[2] != parts[4] {
+				continue
+			}
+			first, _ := strconv.Atoi(parts[1])
+			second, _ := strconv.Atoi(parts[3])
+			third, _ := strconv.Atoi(parts[5])
+			if year, ok := bestDate([][3]int{{first, second, third}}); ok {
+				matches = append(matches, Match{Pattern: PatternDate, I: i, J: j, Token: token, Year: year, Separator: parts[2]})
+			}
+		}
+	}
+
+	// Dates inside other dates, like 1987 in 13051987, add nothing
+	var kept []Match
+	for index, match := range matches {
+		covered := false
+		for otherIndex, other := range matches {
+			if otherIndex != index && other.I <= match.I && other.J >= match.J && other.length() > match.length() {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			kept = append(kept, match)
+		}
+	}
+	return kept
+}
+
+func onlyDigits(text string) bool {
+	return strings.IndexFunc(text, func(character rune) bool { return !unicode.IsDigit(character) }) < 0
+}
+
+// digitSplits are where day, month and year may be split in dates without separators
+var digitSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+func digitDate(token string) (int, bool) {
+	var candidates [][3]int
+	for _, split := range digitSplits[len(token)] {
+		first, _ := strconv.Atoi(token[:split[0]])
+		second, _ := strconv.Atoi(token[split[0]:split[1]])
+		third, _ := strconv.Atoi(token[split[1]:])
+		candidates = append(candidates, [3]int{first, second, third})
+	}
+	return bestDate(candidates)
+}
+
+// bestDate reads each candidate as year-month-day, day-month-year or
+// month-day-year and returns the valid year closest to now
+func bestDate(candidates [][3]int) (int, bool) {
+	best, found := 0, false
+	for _, parts := range candidates {
+		orders := [][3]int{
+			{parts[0], parts[1], parts[2]}, // year month day
+			{parts[2], parts[1], parts[0]}, // day month year
+			{parts[2], parts[0], parts[1]}, // month day year
+		}
+		for _, order := range orders {
+			year, ok := validDate(order[0], order[1], order[2])
+			if !ok {
+				continue
+			}
+			if !found || abs(year-referenceYear()) < abs(best-referenceYear()) {
+				best, found = year, true
+			}
+		}
+	}
+	return best, found
+}
+
+func validDate(year, month, day int) (int, bool) {
+	switch {
+	case year < 100:
+		// Two digit years, 87 means 1987 and 13 means 2013
+		if year > 50 {
+			year += 1900
+		} else {
+			year += 2000
+		}
+	case year < 1000 || year > 2050:
+		return 0, false
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return 0, false
+	}
+	return year, true
+}
+
+func referenceYear() int {
+	return time.Now().Year()
+}
+
+func abs(number int) int {
+	if number < 0 {
+		return -number
+	}
+	return number
+}
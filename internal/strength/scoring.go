@@ -0,0 +1,254 @@
+package strength
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	bruteforceCardinality           = 10
+	minSubmatchGuessesSingleChar    = 10
+	minSubmatchGuessesMultiChar     = 50
+	minGuessesBeforeGrowingSequence = 10000
+	minYearSpace                    = 20
+)
+
+// candidate is the best way found to cover the passphrase up to some index
+// with a given number of matches
+type candidate struct {
+	match   Match
+	product float64 // Product of the guesses of all matches so far
+	total   float64 // Guesses for the whole sequence, what is minimized
+}
+
+// mostGuessableSequence finds the sequence of non-overlapping matches, with
+// brute force filling the gaps, that needs the fewest guesses overall. Every
+// extra match multiplies the guesses by the number of orderings and adds a
+// penalty, so short sequences of likely patterns win.
+func mostGuessableSequence(passphrase string, matches []Match) (float64, []Match) {
+	runes := []rune(passphrase)
+	length := len(runes)
+	if length == 0 {
+		return 1, nil
+	}
+
+	matchesByEnd := make([][]Match, length)
+	for _, match := range matches {
+		matchesByEnd[match.J] = append(matchesByEnd[match.J], match)
+	}
+
+	// optimal[k][l] covers runes 0..k with l matches
+	optimal := make([]map[int]candidate, length)
+	for index := range optimal {
+		optimal[index] = map[int]candidate{}
+	}
+
+	update := func(match Match, count int) {
+		end := match.J
+		product := estimateGuesses(&match, length)
+		if count > 1 {
+			product *= optimal[match.I-1][count-1].product
+		}
+		total := factorial(count) * product
+		total += math.Pow(minGuessesBeforeGrowingSequence, float64(count-1))
+
+		// A sequence with fewer matches and fewer guesses makes this one pointless
+		for otherCount, other := range optimal[end] {
+			if otherCount <= count && other.total <= total {
+				return
+			}
+		}
+		optimal[end][count] = candidate{match: match, product: product, total: total}
+	}
+
+	bruteforce := func(i, j int) Match {
+		return Match{Pattern: PatternBruteforce, I: i, J: j, Token: string(runes[i : j+1])}
+	}
+
+	for end := range length {
+		for _, match := range matchesByEnd[end] {
+			if match.I == 0 {
+				update(match, 1)
+				continue
+			}
+			for count := range optimal[match.I-1] {
+				update(match, count+1)
+			}
+		}
+
+		// Brute force from any earlier point, but never two in a row
+		update(bruteforce(0, end), 1)
+		for start := 1; start <= end; start++ {
+			for count, previous := range optimal[start-1] {
+				if previous.match.Pattern != PatternBruteforce {
+					update(bruteforce(start, end), count+1)
+				}
+			}
+		}
+	}
+
+	// Walk back from the cheapest full cover
+	bestCount, bestTotal := 0, math.Inf(1)
+	for count, option := range optimal[length-1] {
+		if option.total < bestTotal {
+			bestCount, bestTotal = count, option.total
+		}
+	}
+
+	sequence := make([]Match, bestCount)
+	end := length - 1
+	for count := bestCount; count > 0; count-- {
+		match := optimal[end][count].match
+		sequence[count-1] = match
+		end = match.I - 1
+	}
+	return bestTotal, sequence
+}
+
+// estimateGuesses sets and returns the guesses needed to find a single match
+func estimateGuesses(match *Match, passphraseLength int) float64 {
+	if match.Guesses > 0 {
+		return match.Guesses
+	}
+
+	minimum := 1.0
+	if match.length() < passphraseLength {
+		minimum = minSubmatchGuessesMultiChar
+		if match.length() == 1 {
+			minimum = minSubmatchGuessesSingleChar
+		}
+	}
+
+	var guesses float64
+	switch match.Pattern {
+	case PatternBruteforce:
+		guesses = math.Pow(bruteforceCardinality, float64(match.length()))
+		if math.IsInf(guesses, 1) {
+			guesses = math.MaxFloat64
+		}
+		// Any other match of the same length should be preferred
+		minimum = minSubmatchGuessesMultiChar + 1
+		if match.length() == 1 {
+			minimum = minSubmatchGuessesSingleChar + 1
+		}
+	case PatternDictionary:
+		guesses = float64(match.Rank) * uppercaseVariations(match.Token) * l33tVariations(*match)
+		if match.Reversed {
+			guesses *= 2
+		}
+	case PatternSpatial:
+		guesses = spatialGuesses(*match)
+	case PatternRepeat:
+		guesses = match.BaseGuesses * float64(match.Repeats)
+	case PatternSequence:
+		guesses = sequenceGuesses(*match)
+	case PatternYear:
+		guesses = float64(max(abs(match.Year-referenceYear()), minYearSpace))
+	case PatternDate:
+		guesses = float64(max(abs(match.Year-referenceYear()), minYearSpace)) * 365
+		if match.Separator != "" {
+			guesses *= 4
+		}
+	}
+
+	match.Guesses = math.Max(guesses, minimum)
+	return match.Guesses
+}
+
+func sequenceGuesses(match Match) float64 {
+	first := []rune(match.Token)[0]
+	var base float64
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		base = 4 // Obvious starting points
+	case unicode.IsDigit(first):
+		base = 10
+	default:
+		base = 26
+	}
+	if !match.Ascending {
+		base *= 2
+	}
+	return base * float64(match.length())
+}
+
+// uppercaseVariations counts the ways to capitalize a word like token
+func uppercaseVariations(token string) float64 {
+	runes := []rune(token)
+	upper, lower := 0, 0
+	for _, character := range runes {
+		switch {
+		case unicode.IsUpper(character):
+			upper++
+		case unicode.IsLower(character):
+			lower++
+		}
+	}
+
+	switch {
+	case upper == 0:
+		return 1
+	case lower == 0, // All caps
+		upper == 1 && unicode.IsUpper(runes[0]),            // Capitalized
+		upper == 1 && unicode.IsUpper(runes[len(runes)-1]): // Last letter
+		return 2
+	}
+
+	variations := 0.0
+	for i := 1; i <= min(upper, lower); i++ {
+		variations += binomial(upper+lower, i)
+	}
+	return variations
+}
+
+// l33tVariations counts the ways to apply the substitutions of match
+func l33tVariations(match Match) float64 {
+	if len(match.Substitutions) == 0 {
+		return 1
+	}
+
+	variations := 1.0
+	token := []rune(strings.ToLower(match.Token))
+	for substituted, letter := range match.Substitutions {
+		substitutedCount, letterCount := 0, 0
+		for _, character := range token {
+			switch character {
+			case substituted:
+				substitutedCount++
+			case letter:
+				letterCount++
+			}
+		}
+
+		if substitutedCount == 0 || letterCount == 0 {
+			variations *= 2
+			continue
+		}
+		possibilities := 0.0
+		for i := 1; i <= min(substitutedCount, letterCount); i++ {
+			possibilities += binomial(substitutedCount+letterCount, i)
+		}
+		variations *= possibilities
+	}
+	return variations
+}
+
+func binomial(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+func factorial(n int) float64 {
+	result := 1.0
+	for i := 2; i <= n; i++ {
+		result *= float64(i)
+	}
+	return result
+}
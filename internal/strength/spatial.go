@@ -0,0 +1,209 @@
+package strength
+
+import (
+	"math"
+	"strings"
+	"sync"
+)
+
+/*
+ * Keyboard walks like "qwerty", "zxcvbn" or "7895123" on the layouts below.
+ * Every key lists its neighbours in a fixed order of directions, so a change
+ * of direction while walking counts as a turn.
+ */
+
+const qwertyLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+
+    qQ wW eE rR tT yY uU iI oO pP [{ ]} \|
+     aA sS dD fF gG hH jJ kK lL ;: '"
+      zZ xX cC vV bB nN mM ,< .> /?
+`
+
+const dvorakLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) [{ ]}
+    '" ,< .> pP yY fF gG cC rR lL /? =+ \|
+     aA oO eE uU iI dD hH tT nN sS -_
+      ;: qQ jJ kK xX bB mM wW vV zZ
+`
+
+const keypadLayout = `
+  / * -
+7 8 9 +
+4 5 6
+1 2 3
+  0 .
+`
+
+// adjacencyGraph maps a key to its neighbours, nil where there is no key
+type adjacencyGraph struct {
+	name          string
+	neighbours    map[rune][]string
+	keys          map[rune]string // The key a character is on, like "aA" for 'A'
+	averageDegree float64
+}
+
+var adjacencyGraphs = sync.OnceValue(func() []*adjacencyGraph {
+	return []*adjacencyGraph{
+		buildGraph("qwerty", qwertyLayout, true),
+		buildGraph("dvorak", dvorakLayout, true),
+		buildGraph("keypad", keypadLayout, false),
+	}
+})
+
+type position struct{ x, y int }
+
+// buildGraph reads a layout drawing. Rows of slanted keyboards are shifted
+// by one character each, so a key touches two keys in the row above and two
+// in the row below.
+func buildGraph(name, layout string, slanted bool) *adjacencyGraph {
+	keys := map[position]string{}
+	lines := strings.Split(layout, "\n")
+	tokenWidth := 0
+
+	for y, line := range lines {
+		slant := 0
+		if slanted {
+			slant = y - 1
+		}
+		column := 0
+		for _, token := range strings.Fields(line) {
+			tokenWidth = len(token) + 1
+			index := strings.Index(line[column:], token) + column
+			column = index + len(token)
+			keys[position{(index - slant) / tokenWidth, y}] = token
+		}
+	}
+
+	graph := &adjacencyGraph{name: name, neighbours: map[rune][]string{}, keys: map[rune]string{}}
+	degrees := 0
+	for at, key := range keys {
+		var neighbours []string
+		for _, next := range adjacentPositions(at, slanted) {
+			neighbour := keys[next]
+			neighbours = append(neighbours, neighbour)
+			if neighbour != "" {
+				degrees++
+			}
+		}
+		for _, character := range key {
+			graph.neighbours[character] = neighbours
+			graph.keys[character] = key
+		}
+	}
+	graph.averageDegree = float64(degrees) / float64(len(keys))
+	return graph
+}
+
+func adjacentPositions(at position, slanted bool) []position {
+	x, y := at.x, at.y
+	if slanted {
+		return []position{{x - 1, y}, {x, y - 1}, {x + 1, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y + 1}}
+	}
+	return []position{{x - 1, y}, {x - 1, y - 1}, {x, y - 1}, {x + 1, y - 1}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}, {x - 1, y + 1}}
+}
+
+// shifted reports whether a character needs Shift on its key
+func (graph *adjacencyGraph) shifted(character rune) bool {
+	key := []rune(graph.keys[character])
+	return len(key) > 1 && key[1] == character
+}
+
+// spatialMatches finds walks of three or more neighbouring keys
+func spatialMatches(runes []rune) []Match {
+	var matches []Match
+	for _, graph := range adjacencyGraphs() {
+		matches = append(matches, graph.walks(runes)...)
+	}
+	return matches
+}
+
+func (graph *adjacencyGraph) walks(runes []rune) []Match {
+	var matches []Match
+	i := 0
+	for i < len(runes)-1 {
+		j := i + 1
+		lastDirection := -1
+		turns := 0
+		shifted := 0
+		if graph.shifted(runes[i]) {
+			shifted = 1
+		}
+
+		for {
+			found := false
+			if j < len(runes) {
+				for direction, neighbour := range graph.neighbours[runes[j-1]] {
+					keyIndex := strings.IndexRune(neighbour, runes[j])
+					if neighbour == "" || keyIndex < 0 {
+						continue
+					}
+					found = true
+					if keyIndex > 0 {
+						shifted++
+					}
+					if direction != lastDirection {
+						turns++
+						lastDirection = direction
+					}
+					break
+				}
+			}
+
+			if found {
+				j++
+				continue
+			}
+			if j-i > 2 {
+				matches = append(matches, Match{
+					Pattern: PatternSpatial,
+					I:       i,
+					J:       j - 1,
+					Token:   string(runes[i:j]),
+					Graph:   graph.name,
+					Turns:   turns,
+					Shifted: shifted,
+				})
+			}
+			i = j
+			break
+		}
+	}
+	return matches
+}
+
+// spatialGuesses counts the walks up to the length and turns of match
+func spatialGuesses(match Match) float64 {
+	var graph *adjacencyGraph
+	for _, candidate := range adjacencyGraphs() {
+		if candidate.name == match.Graph {
+			graph = candidate
+		}
+	}
+	startingPositions := float64(len(graph.neighbours))
+	if graph.name != "keypad" {
+		startingPositions /= 2 // Both characters of a key share the position
+	}
+
+	guesses := 0.0
+	length := match.length()
+	for i := 2; i <= length; i++ {
+		possibleTurns := min(match.Turns, i-1)
+		for j := 1; j <= possibleTurns; j++ {
+			guesses += binomial(i-1, j-1) * startingPositions * math.Pow(graph.averageDegree, float64(j))
+		}
+	}
+
+	if match.Shifted > 0 {
+		unshifted := length - match.Shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			variations := 0.0
+			for i := 1; i <= min(match.Shifted, unshifted); i++ {
+				variations += binomial(match.Shifted+unshifted, i)
+			}
+			guesses *= variations
+		}
+	}
+	return guesses
+}
@@ -0,0 +1,124 @@
+package strength
+
+import (
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+/*
+ * Estimates how hard a passphrase is to guess without asking the server,
+ * following the approach of Dropbox's zxcvbn.
+ *
+ * The passphrase is searched for patterns an attacker tries first: words from
+ * embedded frequency lists (also reversed and with l33t substitutions),
+ * keyboard walks, repeats, sequences and dates. The cheapest way to cover the
+ * whole passphrase with such patterns and brute force gives the number of
+ * guesses, which is mapped onto the same 0-100 scale the server reports for
+ * stored accounts.
+ *
+ * The frequency lists come from zxcvbn (MIT License, Dropbox, Inc.).
+ */
+
+// Result is the outcome of checking a passphrase
+type Result struct {
+	Guesses     float64
+	Score       int // 0-100, like Account.Strength
+	CrackTime   time.Duration
+	Warning     string
+	Suggestions []string
+	Sequence    []Match // The patterns the estimate is based on
+}
+
+// crackGuessesPerSecond assumes an offline attack on a slow password hash
+const crackGuessesPerSecond = 1e4
+
+// Check estimates the strength of a passphrase. Words from userInputs, like
+// the platform or identifier of an account, count as very easy to guess.
+func Check(passphrase string, userInputs ...string) Result {
+	if utf8.RuneCountInString(passphrase) > maxCheckedLength {
+		// Matching is quadratic, the tail of very long input barely changes the verdict
+		passphrase = string([]rune(passphrase)[:maxCheckedLength])
+	}
+
+	matches := omnimatch(passphrase, userInputs)
+	guesses, sequence := mostGuessableSequence(passphrase, matches)
+
+	result := Result{
+		Guesses:   guesses,
+		Score:     ScoreGuesses(guesses),
+		CrackTime: crackTime(guesses),
+		Sequence:  sequence,
+	}
+	result.Warning, result.Suggestions = feedback(result.Score, sequence)
+	return result
+}
+
+// maxCheckedLength bounds the work Check does
+const maxCheckedLength = 100
+
+// Estimate returns only the 0-100 score of a passphrase
+func Estimate(passphrase string) int {
+	return Check(passphrase).Score
+}
+
+// ScoreGuesses maps a number of guesses onto the 0-100 scale, 10^20 guesses
+// and more score 100
+func ScoreGuesses(guesses float64) int {
+	if guesses <= 1 {
+		return 0
+	}
+	return int(math.Min(math.Round(math.Log10(guesses)*5), 100))
+}
+
+// Score maps entropy in bits onto the 0-100 scale
+func Score(bits float64) int {
+	return ScoreGuesses(math.Pow(2, bits))
+}
+
+// CrackTimeForBits is how long guessing a passphrase with this entropy takes on average
+func CrackTimeForBits(bits float64) time.Duration {
+	return crackTime(math.Pow(2, bits) / 2)
+}
+
+func crackTime(guesses float64) time.Duration {
+	seconds := guesses / crackGuessesPerSecond
+	if seconds >= math.MaxInt64/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// DisplayTime writes a crack time in words, like "3 hours" or "centuries"
+func DisplayTime(duration time.Duration) string {
+	seconds := duration.Seconds()
+	units := []struct {
+		name    string
+		seconds float64
+	}{
+		{"year", 365.25 * 24 * 3600},
+		{"month", 30.44 * 24 * 3600},
+		{"day", 24 * 3600},
+		{"hour", 3600},
+		{"minute", 60},
+		{"second", 1},
+	}
+
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds >= 100*units[0].seconds:
+		return "centuries"
+	}
+	for _, unit := range units {
+		if seconds >= unit.seconds {
+			count := int(math.Round(seconds / unit.seconds))
+			if count == 1 {
+				return "1 " + unit.name
+			}
+			return strconv.Itoa(count) + " " + unit.name + "s"
+		}
+	}
+	return "less than a second"
+}
@@ -0,0 +1,151 @@
+package strength
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckPatterns(t *testing.T) {
+	account := []string{"jane.doe@example.com"}
+
+	tests := []struct {
+		name       string
+		passphrase string
+		userInputs []string
+		patterns   []string // Of the sequence the estimate is based on
+		word       string   // Of the first match, for dictionary matches
+		maxScore   int
+	}{
+		{"common password", "password", nil, []string{PatternDictionary}, "password", 5},
+		{"reversed", "drowssap", nil, []string{PatternDictionary}, "password", 5},
+		{"l33t", "$ecur1ty", nil, []string{PatternDictionary}, "security", 20},
+		{"l33t digits", "m0nk3y", nil, []string{PatternDictionary}, "monkey", 10},
+		{"keyboard walk", "qwsxcde", nil, []string{PatternSpatial}, "", 35},
+		{"keypad walk", "7412369", nil, []string{PatternSpatial}, "", 25},
+		{"repeat", "aaaaaaaa", nil, []string{PatternRepeat}, "", 10},
+		{"repeated base", "abcabcabc", nil, []string{PatternRepeat}, "", 10},
+		{"sequence", "abcdefgh", nil, []string{PatternSequence}, "", 10},
+		{"sequence with a step", "13579", nil, []string{PatternSequence}, "", 10},
+		{"year", "1987", nil, []string{PatternYear}, "", 10},
+		{"separated date", "11/05/1987", nil, []string{PatternDate}, "", 25},
+		{"digit date", "19871105", nil, []string{PatternDate}, "", 25},
+		{"user inputs", "janedoe", account, []string{PatternDictionary, PatternDictionary}, "jane", 25},
+		{"random", "X9#vLq!2@Rt7Zp$w", nil, []string{PatternBruteforce}, "", 100},
+		{"empty", "", nil, nil, "", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Check(test.passphrase, test.userInputs...)
+
+			var patterns []string
+			for _, match := range result.Sequence {
+				patterns = append(patterns, match.Pattern)
+			}
+			if !reflect.DeepEqual(patterns, test.patterns) {
+				t.Errorf("Check(%q) matched %q, want %q", test.passphrase, patterns, test.patterns)
+			}
+			if test.word != "" && result.Sequence[0].Word != test.word {
+				t.Errorf("Check(%q) matched the word %q, want %q", test.passphrase, result.Sequence[0].Word, test.word)
+			}
+			if result.Score > test.maxScore {
+				t.Errorf("Check(%q) scored %d, want at most %d", test.passphrase, result.Score, test.maxScore)
+			}
+			if result.Score < feedbackBelowScore && result.Warning == "" && test.passphrase != "" {
+				t.Errorf("Check(%q) scored %d without a warning", test.passphrase, result.Score)
+			}
+		})
+	}
+}
+
+func TestCheckUserInputs(t *testing.T) {
+	for _, passphrase := range []string{"janedoe", "examplecom"} {
+		without := Check(passphrase)
+		with := Check(passphrase, "jane.doe@example.com")
+		if with.Guesses >= without.Guesses {
+			t.Errorf("Check(%q) needs %g guesses with the account details, %g without", passphrase, with.Guesses, without.Guesses)
+		}
+		if with.Sequence[0].Dictionary != "user inputs" {
+			t.Errorf("Check(%q) matched %q, want the user inputs", passphrase, with.Sequence[0].Dictionary)
+		}
+	}
+}
+
+func TestCheckStrongPassphrase(t *testing.T) {
+	result := Check("correct horse battery staple")
+	if result.Score != 100 || result.Warning != "" || len(result.Suggestions) != 0 {
+		t.Errorf("Check() = score %d, warning %q, suggestions %q, want a strong passphrase",
+			result.Score, result.Warning, result.Suggestions)
+	}
+}
+
+func TestCheckTruncates(t *testing.T) {
+	long := strings.Repeat("x9#L", 100)
+	result := Check(long)
+	if last := result.Sequence[len(result.Sequence)-1]; last.J != maxCheckedLength-1 {
+		t.Errorf("Check() of %d runes matched up to %d, want %d", len(long), last.J, maxCheckedLength-1)
+	}
+	if want := Check(long[:maxCheckedLength]); result.Guesses != want.Guesses {
+		t.Errorf("Check() = %g guesses, want %g like its first %d runes", result.Guesses, want.Guesses, maxCheckedLength)
+	}
+}
+
+func TestScoreGuesses(t *testing.T) {
+	tests := []struct {
+		guesses float64
+		want    int
+	}{
+		{0, 0},
+		{1, 0},
+		{10, 5},
+		{1e10, 50},
+		{1e20, 100},
+		{1e30, 100},
+		{math.Inf(1), 100},
+	}
+	for _, test := range tests {
+		if got := ScoreGuesses(test.guesses); got != test.want {
+			t.Errorf("ScoreGuesses(%g) = %d, want %d", test.guesses, got, test.want)
+		}
+	}
+
+	if got := Score(0); got != 0 {
+		t.Errorf("Score(0) = %d, want 0", got)
+	}
+	if got := Score(200); got != 100 {
+		t.Errorf("Score(200) = %d, want 100", got)
+	}
+}
+
+func TestDisplayTime(t *testing.T) {
+	year := time.Duration(365.25 * 24 * float64(time.Hour))
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{0, "less than a second"},
+		{500 * time.Millisecond, "less than a second"},
+		{time.Second, "1 second"},
+		{59 * time.Second, "59 seconds"},
+		{90 * time.Second, "2 minutes"},
+		{time.Hour, "1 hour"},
+		{3 * 24 * time.Hour, "3 days"},
+		{45 * 24 * time.Hour, "1 month"},
+		{year, "1 year"},
+		{99 * year, "99 years"},
+		{100 * year, "centuries"},
+		{time.Duration(math.MaxInt64), "centuries"},
+	}
+	for _, test := range tests {
+		if got := DisplayTime(test.duration); got != test.want {
+			t.Errorf("DisplayTime(%s) = %q, want %q", test.duration, got, test.want)
+		}
+	}
+
+	if got := crackTime(math.Inf(1)); got != time.Duration(math.MaxInt64) {
+		t.Errorf("crackTime(+Inf) = %s, want the longest duration", got)
+	}
+}
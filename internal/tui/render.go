@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"passenger-go-cli/internal/fuzzy"
+	"passenger-go-cli/internal/utilities"
+
+	"golang.org/x/term"
+)
+
+const (
+	styleReset     = "\033[0m"
+	styleInverse   = "\033[7m"
+	styleDim       = "\033[2m"
+	styleBold      = "\033[1m"
+	styleHighlight = "\033[1;33m"
+	minimumWidth   = 40
+	minimumHeight  = 8
+	browseHelp     = "↑/↓ Move  / Search  r Reveal  c Copy  e Edit  n New  d Delete  g Generate  R Reload  q Quit"
+	searchHelp     = "Type to filter  ↑/↓ Move  Enter Done  Esc Clear"
+)
+
+// listHeight is the number of account rows that fit on the screen
+func (session *app) listHeight() int {
+	return max(session.height-3, 1)
+}
+
+// render draws the whole screen in one write to avoid flicker
+func (session *app) render() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	session.width, session.height = width, height
+
+	var frame strings.Builder
+	frame.WriteString("\033[H")
+
+	if width < minimumWidth || height < minimumHeight {
+		frame.WriteString("\033[2J\033[HTerminal is too small, resize or press q to quit")
+		os.Stdout.WriteString(frame.String())
+		return
+	}
+
+	listWidth := max(width*2/5, 24)
+	detailWidth := width - listWidth - 3
+
+	// Header: title and search bar
+	search := "Search: " + string(session.query)
+	if session.mode == modeSearch {
+		search += "█"
+	} else if len(session.query) == 0 {
+		search = "Press / to search"
+	}
+	header := styleInverse + styleBold + " Passenger Go " + styleReset + " " + search
+	frame.WriteString(utilities.FitWidth(header, width) + "\r\n")
+
+	// Body: account list on the left, details on the right
+	rows := session.listHeight()
+	if session.selected < session.offset {
+		session.offset = session.selected
+	}
+	if session.selected >= session.offset+rows {
+		session.offset = session.selected - rows + 1
+	}
+
+	details := session.detailLines(detailWidth)
+	for row := 0; row < rows; row++ {
+		left := ""
+		index := session.offset + row
+		if index < len(session.matches) {
+			left = listLine(session.matches[index], index == session.selected, listWidth)
+		} else if index == 0 {
+			left = styleDim + " No accounts" + styleReset
+		}
+
+		right := ""
+		if row < len(details) {
+			right = details[row]
+		}
+
+		frame.WriteString(utilities.FitWidth(left, listWidth) + " │ " + utilities.FitWidth(right, detailWidth) + "\r\n")
+	}
+
+	// Footer: counter and status or help
+	counter := strconv.Itoa(len(session.matches)) + "/" + strconv.Itoa(len(session.accounts))
+	frame.WriteString(utilities.FitWidth(styleDim+strings.Repeat("─", listWidth)+"┴"+strings.Repeat("─", width-listWidth-1)+styleReset, width) + "\r\n")
+
+	footer := session.status
+	if footer == "" {
+		footer = browseHelp
+		if session.mode == modeSearch {
+			footer = searchHelp
+		}
+		footer = styleDim + footer + styleReset
+	}
+	frame.WriteString(utilities.FitWidth(" "+counter+"  "+footer, width))
+
+	os.Stdout.WriteString(frame.String())
+}
+
+func listLine(match fuzzy.AccountMatch, selected bool, width int) string {
+	if selected {
+		// Plain text keeps the inverse bar continuous across the row
+		return styleInverse + utilities.FitWidth(" "+match.Account.Platform+" "+match.Account.Identifier, width) + styleReset
+	}
+	return " " +
+		fuzzy.Highlight(match.Account.Platform, match.Platform, styleHighlight, styleReset) + " " +
+		styleDim + match.Account.Identifier + styleReset
+}
+
+// detailLines describes the selected account, wrapping notes to the pane
+func (session *app) detailLines(width int) []string {
+	account, ok := session.current()
+	if !ok {
+		return nil
+	}
+
+	passphrase := styleDim + "•••••••• (r to reveal)" + styleReset
+	if revealed, ok := session.revealed[account.ID]; ok {
+		passphrase = revealed
+	}
+
+	lines := []string{
+		styleBold + account.Platform + styleReset,
+		"",
+		"Identifier  " + account.Identifier,
+		"URL         " + account.URL,
+		"Passphrase  " + passphrase,
+		"Strength    " + utilities.StrengthBar(account.Strength) + " " + strconv.Itoa(account.Strength) + " (" + utilities.StrengthLabel(account.Strength) + ")",
+		"ID          " + styleDim + account.ID + styleReset,
+		"",
+		styleBold + "Notes" + styleReset,
+	}
+
+	if account.Notes == "" {
+		return append(lines, styleDim+"<no-notes-available>"+styleReset)
+	}
+	for _, line := range strings.Split(account.Notes, "\n") {
+		lines = append(lines, wrap(line, width)...)
+	}
+	return lines
+}
+
+// wrap splits a line into pieces that fit width runes
+func wrap(line string, width int) []string {
+	runes := []rune(line)
+	if len(runes) <= width || width <= 0 {
+		return []string{line}
+	}
+
+	var pieces []string
+	for len(runes) > width {
+		pieces = append(pieces, string(runes[:width]))
+		runes = runes[width:]
+	}
+	return append(pieces, string(runes))
+}
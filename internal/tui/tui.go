@@ -0,0 +1,363 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"passenger-go-cli/internal/api"
+	"passenger-go-cli/internal/fuzzy"
+	"passenger-go-cli/internal/schemas"
+	"passenger-go-cli/internal/utilities"
+
+	"golang.org/x/term"
+)
+
+// Handlers are flows that need the normal terminal, the TUI steps aside while
+// they run. Copy and Deleted run within the TUI and must not print.
+type Handlers struct {
+	Edit    func(account schemas.Account) error
+	Create  func() error
+	Copy    func(secret, what string) (string, error) // Copies and schedules clearing, returns the status
+	Deleted func(account schemas.Account) error       // Cleans up local state of a deleted account
+}
+
+type mode int
+
+const (
+	modeBrowse mode = iota
+	modeSearch
+	modeConfirmDelete
+)
+
+// app holds the state of a running TUI session
+type app struct {
+	handlers Handlers
+	accounts []schemas.Account
+	matches  []fuzzy.AccountMatch
+	query    []rune
+	selected int
+	offset   int
+	mode     mode
+	status   string
+	revealed map[string]string // Account ID to passphrase, only while revealed
+	width    int
+	height   int
+	state    *term.State
+}
+
+// Run opens the full-screen interface and blocks until the user quits
+func Run(handlers Handlers) (err error) {
+	if !utilities.IsTerminal(os.Stdin) || !utilities.IsTerminal(os.Stdout) {
+		return fmt.Errorf("the TUI needs an interactive terminal")
+	}
+
+	accounts, err := api.GetAccounts()
+	if err != nil {
+		return err
+	}
+
+	session := &app{
+		handlers: handlers,
+		accounts: accounts,
+		revealed: map[string]string{},
+	}
+	session.filter()
+
+	if err := session.enter(); err != nil {
+		return err
+	}
+	// Whatever happens, including panics, hand back a usable terminal
+	defer func() {
+		session.leave()
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("TUI crashed: %v", recovered)
+		}
+	}()
+
+	resized, terminated, stopSignals := utilities.WatchTerminalSignals()
+	defer stopSignals()
+
+	// No read may be in flight while another flow owns the terminal
+	keys := utilities.NewKeyPump(utilities.NewKeyReader(os.Stdin))
+	defer keys.Close()
+
+	for {
+		session.render()
+
+		select {
+		case <-resized:
+			continue
+		case <-terminated:
+			return nil
+		case result := <-keys.Next():
+			key, err := keys.Take(result)
+			if err != nil {
+				return err
+			}
+			if quit := session.handleKey(key); quit {
+				return nil
+			}
+		}
+	}
+}
+
+// enter switches to the alternate screen and raw mode
+func (session *app) enter() error {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to set terminal raw mode: %w", err)
+	}
+	session.state = state
+	os.Stdout.WriteString("\033[?1049h\033[?25l\033[?2004h")
+	return nil
+}
+
+// leave restores the terminal to how it was before enter
+func (session *app) leave() {
+	os.Stdout.WriteString("\033[?2004l\033[?25h\033[?1049l")
+	if session.state != nil {
+		term.Restore(int(os.Stdin.Fd()), session.state)
+		session.state = nil
+	}
+}
+
+// suspend runs a flow on the normal screen and comes back afterwards
+func (session *app) suspend(flow func() error) {
+	session.leave()
+
+	err := flow()
+	if err != nil {
+		os.Stderr.WriteString("❌ " + err.Error() + "\n")
+	}
+	os.Stderr.WriteString("\nPress Enter to return to the vault...")
+	var discard [64]byte
+	os.Stdin.Read(discard[:])
+
+	if err := session.enter(); err != nil {
+		panic(err)
+	}
+	session.reload()
+	if err != nil {
+		session.status = "❌ " + err.Error()
+	}
+}
+
+// reload fetches accounts again, keeping the selection where possible
+func (session *app) reload() {
+	selectedID := ""
+	if account, ok := session.current(); ok {
+		selectedID = account.ID
+	}
+
+	accounts, err := api.GetAccounts()
+	if err != nil {
+		session.status = "❌ Failed to reload accounts: " + err.Error()
+		return
+	}
+	session.accounts = accounts
+	session.filter()
+
+	for index, match := range session.matches {
+		if match.Account.ID == selectedID {
+			session.selected = index
+		}
+	}
+}
+
+func (session *app) filter() {
+	if strings.TrimSpace(string(session.query)) == "" {
+		session.matches = make([]fuzzy.AccountMatch, len(session.accounts))
+		for index, account := range session.accounts {
+			session.matches[index] = fuzzy.AccountMatch{Account: account}
+		}
+	} else {
+		session.matches = fuzzy.RankAccounts(string(session.query), session.accounts)
+	}
+	session.selected = 0
+	session.offset = 0
+}
+
+func (session *app) current() (schemas.Account, bool) {
+	if len(session.matches) == 0 {
+		return schemas.Account{}, false
+	}
+	return session.matches[session.selected].Account, true
+}
+
+func (session *app) move(delta int) {
+	if len(session.matches) == 0 {
+		return
+	}
+	session.selected = min(max(session.selected+delta, 0), len(session.matches)-1)
+}
+
+// handleKey applies a key press and reports whether the TUI should quit
+func (session *app) handleKey(key utilities.Key) bool {
+	if key.Is('c') {
+		return true
+	}
+
+	switch session.mode {
+	case modeSearch:
+		session.handleSearchKey(key)
+		return false
+	case modeConfirmDelete:
+		session.mode = modeBrowse
+		if key.Kind == utilities.KeyRune && (key.Rune == 'y' || key.Rune == 'Y') {
+			session.deleteCurrent()
+		} else {
+			session.status = "Delete cancelled"
+		}
+		return false
+	}
+
+	session.status = ""
+	switch {
+	case key.Kind == utilities.KeyUp, key.Kind == utilities.KeyRune && key.Rune == 'k':
+		session.move(-1)
+	case key.Kind == utilities.KeyDown, key.Kind == utilities.KeyRune && key.Rune == 'j':
+		session.move(1)
+	case key.Kind == utilities.KeyPageUp:
+		session.move(-session.listHeight())
+	case key.Kind == utilities.KeyPageDown:
+		session.move(session.listHeight())
+	case key.Kind == utilities.KeyHome:
+		session.selected = 0
+	case key.Kind == utilities.KeyEnd:
+		session.move(len(session.matches))
+	case key.Kind == utilities.KeyEscape:
+		if len(session.query) > 0 {
+			session.query = nil
+			session.filter()
+		}
+	case key.Kind != utilities.KeyRune:
+		return false
+	}
+
+	account, hasAccount := session.current()
+	switch key.Rune {
+	case 'q':
+		return true
+	case '/':
+		session.mode = modeSearch
+	case 'r':
+		if hasAccount {
+			session.toggleReveal(account)
+		}
+	case 'c':
+		if hasAccount {
+			session.copyPassphrase(account)
+		}
+	case 'e':
+		if hasAccount && session.handlers.Edit != nil {
+			session.suspend(func() error { return session.handlers.Edit(account) })
+		}
+	case 'n':
+		if session.handlers.Create != nil {
+			session.suspend(session.handlers.Create)
+		}
+	case 'd':
+		if hasAccount {
+			session.mode = modeConfirmDelete
+			session.status = "Delete " + account.Platform + " (" + account.Identifier + ")? y/N"
+		}
+	case 'g':
+		session.generate()
+	case 'R':
+		session.reload()
+		session.status = "Reloaded " + fmt.Sprint(len(session.accounts)) + " accounts"
+	}
+	return false
+}
+
+func (session *app) handleSearchKey(key utilities.Key) {
+	switch {
+	case key.Kind == utilities.KeyEnter, key.Kind == utilities.KeyTab:
+		session.mode = modeBrowse
+	case key.Kind == utilities.KeyEscape:
+		session.mode = modeBrowse
+		session.query = nil
+		session.filter()
+	case key.Kind == utilities.KeyUp:
+		session.move(-1)
+	case key.Kind == utilities.KeyDown:
+		session.move(1)
+	case key.Kind == utilities.KeyBackspace:
+		if len(session.query) > 0 {
+			session.query = session.query[:len(session.query)-1]
+			session.filter()
+		}
+	case key.Is('u'):
+		session.query = nil
+		session.filter()
+	case key.Kind == utilities.KeyRune:
+		session.query = append(session.query, key.Rune)
+		session.filter()
+	case key.Kind == utilities.KeyPaste:
+		session.query = append(session.query, []rune(strings.ReplaceAll(key.Text, "\n", " "))...)
+		session.filter()
+	}
+}
+
+func (session *app) toggleReveal(account schemas.Account) {
+	if _, ok := session.revealed[account.ID]; ok {
+		delete(session.revealed, account.ID)
+		return
+	}
+
+	passphrase, err := api.GetAccountPassphrase(account.ID)
+	if err != nil {
+		session.status = "❌ Failed to get passphrase: " + err.Error()
+		return
+	}
+	session.revealed[account.ID] = passphrase
+}
+
+func (session *app) copyPassphrase(account schemas.Account) {
+	passphrase, err := api.GetAccountPassphrase(account.ID)
+	if err != nil {
+		session.status = "❌ Failed to get passphrase: " + err.Error()
+		return
+	}
+
+	message, err := session.handlers.Copy(passphrase, "the passphrase of "+account.Platform)
+	if err != nil {
+		session.status = "❌ Failed to copy: " + err.Error()
+		return
+	}
+	session.status = message
+}
+
+func (session *app) deleteCurrent() {
+	account, ok := session.current()
+	if !ok {
+		return
+	}
+
+	if err := api.DeleteAccount(account.ID); err != nil {
+		session.status = "❌ Failed to delete account: " + err.Error()
+		return
+	}
+	delete(session.revealed, account.ID)
+	session.reload()
+	session.status = "✅ Deleted " + account.Platform + " (" + account.Identifier + ")"
+	if err := session.handlers.Deleted(account); err != nil {
+		session.status += ", ⚠️ failed to forget the passphrase age: " + err.Error()
+	}
+}
+
+func (session *app) generate() {
+	passphrase, err := api.GeneratePassphrase(32)
+	if err != nil {
+		session.status = "❌ Failed to generate passphrase: " + err.Error()
+		return
+	}
+
+	message, err := session.handlers.Copy(passphrase, "the generated passphrase")
+	if err != nil {
+		session.status = "❌ Failed to copy the generated passphrase: " + err.Error()
+		return
+	}
+	session.status = message
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strings"
 	"syscall"
 
 	"golang.org/x/term"
@@ -32,3 +33,16 @@ func ReadValue(value string, echo bool, required bool) (string, error) {
 	}
 	return string(byteValue), nil
 }
+
+// Confirm asks a yes/no question on the terminal, anything but y/yes is a no
+func Confirm(question string) (bool, error) {
+	os.Stderr.WriteString(question + " [y/N]: ")
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
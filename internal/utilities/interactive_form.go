@@ -1,11 +1,9 @@
 package utilities
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"strings"
-	"syscall"
 
 	"golang.org/x/term"
 )
@@ -18,15 +16,41 @@ type FormField struct {
 	DefaultValue string
 	IsPassword   bool
 	IsRequired   bool
+	IsMultiline  bool
+	Validators   []Validator
+
+	runes    []rune // Value being edited
+	cursor   int    // Rune index of the cursor in runes
+	err      error  // Validation message shown under the field
+	revealed bool   // Password shown in clear text
+	actions  []FormAction
+	meter    func(value string) (int, string)
+}
+
+// FormAction replaces the value of a field when its Ctrl hotkey is pressed
+type FormAction struct {
+	Key   rune // Lowercase letter pressed together with Ctrl
+	Label string
+	Run   func(value string) (string, error)
 }
 
 // InteractiveForm handles the interactive form input
 type InteractiveForm struct {
-	fields        []*FormField
-	current       int
-	originalState *term.State
+	fields  []*FormField
+	current int
+
+	// Where the cursor was left by the last render, relative to the first line
+	cursorRow int
 }
 
+const (
+	formGuide          = "Guide: Tab/↑/↓ Navigate | Enter Confirm | Ctrl+D Clear Field | Ctrl+S Save & Quit | Ctrl+C Quit"
+	formMultilineGuide = "Enter inserts a new line in this field, use Tab or ↓ on the last line to move on"
+	styleError         = "\033[31m"
+	styleFaint         = "\033[2m"
+	styleNormal        = "\033[0m"
+)
+
 // NewInteractiveForm creates a new interactive form
 func NewInteractiveForm() *InteractiveForm {
 	return &InteractiveForm{
@@ -37,26 +61,62 @@ func NewInteractiveForm() *InteractiveForm {
 
 // AddField adds a field to the form
 func (form *InteractiveForm) AddField(key, label string, isPassword, isRequired bool) {
-	form.fields = append(form.fields, &FormField{
-		Key:          key,
-		Label:        label,
-		Value:        "",
-		DefaultValue: "",
-		IsPassword:   isPassword,
-		IsRequired:   isRequired,
-	})
+	form.AddFieldWithDefault(key, label, "", isPassword, isRequired)
 }
 
 // AddFieldWithDefault adds a field to the form with a default value
 func (form *InteractiveForm) AddFieldWithDefault(key, label, defaultValue string, isPassword, isRequired bool) {
-	form.fields = append(form.fields, &FormField{
+	field := &FormField{
 		Key:          key,
 		Label:        label,
 		Value:        defaultValue,
 		DefaultValue: defaultValue,
 		IsPassword:   isPassword,
 		IsRequired:   isRequired,
-	})
+		runes:        []rune(defaultValue),
+		cursor:       len([]rune(defaultValue)),
+	}
+	if isRequired {
+		field.Validators = append(field.Validators, ValidateRequired)
+	}
+	form.fields = append(form.fields, field)
+}
+
+// SetMultiline lets a field hold several lines, Enter then inserts a new line
+func (form *InteractiveForm) SetMultiline(key string) {
+	if field := form.field(key); field != nil && !field.IsPassword {
+		field.IsMultiline = true
+	}
+}
+
+// AddValidators adds checks that run when leaving the field and before saving
+func (form *InteractiveForm) AddValidators(key string, validators ...Validator) {
+	if field := form.field(key); field != nil {
+		field.Validators = append(field.Validators, validators...)
+	}
+}
+
+// AddAction binds a Ctrl hotkey that replaces the value of a field
+func (form *InteractiveForm) AddAction(key string, action FormAction) {
+	if field := form.field(key); field != nil {
+		field.actions = append(field.actions, action)
+	}
+}
+
+// SetStrengthMeter shows a live 0-100 strength bar under a field, with a short hint next to it
+func (form *InteractiveForm) SetStrengthMeter(key string, meter func(value string) (int, string)) {
+	if field := form.field(key); field != nil {
+		field.meter = meter
+	}
+}
+
+func (form *InteractiveForm) field(key string) *FormField {
+	for _, field := range form.fields {
+		if field.Key == key {
+			return field
+		}
+	}
+	return nil
 }
 
 // GetValues returns all field values as a map
@@ -73,232 +133,459 @@ func (form *InteractiveForm) Run() error {
 	if len(form.fields) == 0 {
 		return fmt.Errorf("no fields defined")
 	}
+	if !IsTerminal(os.Stdin) {
+		return fmt.Errorf("the form needs an interactive terminal")
+	}
 
-	// Store original terminal state
-	var err error
-	form.originalState, err = term.GetState(int(os.Stdin.Fd()))
+	originalState, err := term.MakeRaw(int(os.Stdin.Fd()))
 	if err != nil {
-		return fmt.Errorf("failed to get terminal state: %w", err)
+		return fmt.Errorf("failed to set terminal raw mode: %w", err)
 	}
-
-	// Ensure terminal state is restored on exit
+	// Ensure terminal state is restored on every exit path
 	defer func() {
-		if form.originalState != nil {
-			term.Restore(int(os.Stdin.Fd()), form.originalState)
-		}
-		fmt.Println() // Add newline at the end
+		os.Stdout.WriteString("\033[?2004l")
+		term.Restore(int(os.Stdin.Fd()), originalState)
 	}()
+	os.Stdout.WriteString("\033[?2004h") // Bracketed paste
 
-	fmt.Println("Guide: ↑/↓ Navigate | Enter Confirm | Ctrl+D Clear Field | Ctrl+S Save & Quit | Ctrl+C Quit")
-	fmt.Println()
+	resized, terminated, stopSignals := WatchTerminalSignals()
+	defer stopSignals()
 
-	for {
-		form.displayForm()
-		action := form.getUserInput()
+	keys := NewKeyPump(NewKeyReader(os.Stdin))
+	defer keys.Close()
 
-		switch action {
-		case "up":
-			if form.current > 0 {
-				form.current--
-			}
-		case "down":
-			if form.current < len(form.fields)-1 {
-				form.current++
-			}
-		case "enter":
-			if form.validateCurrentField("") {
-				if form.current == len(form.fields)-1 {
-					// Last field completed, finish form
-					return nil
-				}
-				form.current++
+	for {
+		form.render()
+
+		select {
+		case <-resized:
+			continue
+		case <-terminated:
+			form.finish()
+			return fmt.Errorf("form terminated")
+		case result := <-keys.Next():
+			key, err := keys.Take(result)
+			if err != nil {
+				form.finish()
+				return err
 			}
-		case "clear":
-			if form.validateCurrentField("clear") {
-				if form.current == len(form.fields)-1 {
-					return nil
-				}
-				form.current++
+
+			done, err := form.handleKey(key)
+			if done || err != nil {
+				form.finish()
+				return err
 			}
-		case "savequit":
-			return nil
-		case "quit":
-			return fmt.Errorf("form cancelled by user")
 		}
 	}
 }
 
-// displayForm shows the current form state
-func (form *InteractiveForm) displayForm() {
-	// Clear screen (simple approach)
-	fmt.Print("\033[H\033[2J")
-
-	fmt.Println("Guide: ↑/↓ Navigate | Enter Confirm | Ctrl+D Clear Field | Ctrl+S Save & Quit | Ctrl+C Quit")
-	fmt.Println()
+// handleKey applies a key press, it reports whether the form is complete
+func (form *InteractiveForm) handleKey(key Key) (bool, error) {
+	field := form.fields[form.current]
 
-	for i, field := range form.fields {
-		indicator := " "
-		if i == form.current {
-			indicator = ">"
+	switch {
+	case key.Is('c'):
+		return false, fmt.Errorf("form cancelled by user")
+	case key.Is('s'):
+		return form.submit(), nil
+	case key.Is('d'):
+		field.runes, field.cursor, field.err = nil, 0, nil
+	case key.Is('r') && field.IsPassword:
+		field.revealed = !field.revealed
+	case key.Kind == KeyCtrl && field.action(key.Rune) != nil:
+		field.run(field.action(key.Rune))
+	case key.Kind == KeyEnter && field.IsMultiline:
+		field.insert([]rune{'\n'})
+	case key.Kind == KeyEnter:
+		if !form.validate(field) {
+			return false, nil
+		}
+		if form.current == len(form.fields)-1 {
+			return form.submit(), nil
+		}
+		form.focus(form.current + 1)
+	case key.Kind == KeyTab:
+		form.focus(form.current + 1)
+	case key.Kind == KeyShiftTab:
+		form.focus(form.current - 1)
+	case key.Kind == KeyUp:
+		if !field.IsMultiline || !field.moveLine(-1) {
+			form.focus(form.current - 1)
+		}
+	case key.Kind == KeyDown:
+		if !field.IsMultiline || !field.moveLine(1) {
+			form.focus(form.current + 1)
+		}
+	case key.Kind == KeyLeft && key.Alt, key.Kind == KeyAlt && key.Rune == 'b':
+		field.cursor = field.wordStart()
+	case key.Kind == KeyRight && key.Alt, key.Kind == KeyAlt && key.Rune == 'f':
+		field.cursor = field.wordEnd()
+	case key.Kind == KeyLeft, key.Is('b'):
+		field.cursor = max(field.cursor-1, 0)
+	case key.Kind == KeyRight, key.Is('f'):
+		field.cursor = min(field.cursor+1, len(field.runes))
+	case key.Kind == KeyHome, key.Is('a'):
+		field.cursor = field.lineStart()
+	case key.Kind == KeyEnd, key.Is('e'):
+		field.cursor = field.lineEnd()
+	case key.Kind == KeyBackspace && key.Alt, key.Is('w'):
+		field.remove(field.wordStart(), field.cursor)
+	case key.Kind == KeyBackspace:
+		field.remove(field.cursor-1, field.cursor)
+	case key.Kind == KeyDelete:
+		field.remove(field.cursor, field.cursor+1)
+	case key.Is('u'):
+		field.remove(field.lineStart(), field.cursor)
+	case key.Is('k'):
+		field.remove(field.cursor, field.lineEnd())
+	case key.Kind == KeyPaste:
+		text := strings.ReplaceAll(key.Text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\r", "\n")
+		if !field.IsMultiline {
+			// A trailing newline from copying a whole line is not part of the value
+			text = strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", " ")
 		}
+		field.insert([]rune(text))
+	case key.Kind == KeyRune:
+		field.insert([]rune{key.Rune})
+	}
 
-		// Use Value for display (empty string means cleared field)
-		displayValue := field.Value
+	return false, nil
+}
 
-		if field.IsPassword && displayValue != "" {
-			displayValue = strings.Repeat("*", len(displayValue))
-		}
+// focus moves to another field, checking the one being left
+func (form *InteractiveForm) focus(index int) {
+	if index < 0 || index >= len(form.fields) {
+		return
+	}
+	form.validate(form.fields[form.current])
+	form.current = index
+}
 
-		if displayValue == "" {
-			fmt.Printf("%s %s: \n", indicator, field.Label)
-		} else {
-			fmt.Printf("%s %s: %s\n", indicator, field.Label, displayValue)
+// validate runs the field validators and keeps the first message for display
+func (form *InteractiveForm) validate(field *FormField) bool {
+	field.Value = field.value()
+	field.err = nil
+	// A value saved before the validators existed must not block other changes
+	if field.Value != "" && field.Value == field.DefaultValue {
+		return true
+	}
+	for _, validator := range field.Validators {
+		if err := validator(field.Value); err != nil {
+			field.err = err
+			return false
 		}
 	}
+	return true
 }
 
-// getUserInput handles user input and returns the action
-func (form *InteractiveForm) getUserInput() string {
-	// Set terminal to raw mode for single character input
-	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
-	if err != nil {
-		return "quit"
+// submit validates every field and focuses the first invalid one
+func (form *InteractiveForm) submit() bool {
+	firstInvalid := -1
+	for index, field := range form.fields {
+		if !form.validate(field) && firstInvalid < 0 {
+			firstInvalid = index
+		}
+	}
+	if firstInvalid >= 0 {
+		form.current = firstInvalid
+		return false
 	}
+	return true
+}
 
-	// Always restore the raw state we just set
-	defer func() {
-		if oldState != nil {
-			term.Restore(int(os.Stdin.Fd()), oldState)
+func (field *FormField) action(key rune) *FormAction {
+	for index := range field.actions {
+		if field.actions[index].Key == key {
+			return &field.actions[index]
 		}
-	}()
+	}
+	return nil
+}
 
-	// Read single character
-	var buf [1]byte
-	_, err = os.Stdin.Read(buf[:])
+// run replaces the value with the action result, failures are shown like validation errors
+func (field *FormField) run(action *FormAction) {
+	value, err := action.Run(string(field.runes))
 	if err != nil {
-		return "quit"
+		field.err = fmt.Errorf("%s failed: %w", strings.ToLower(action.Label), err)
+		return
 	}
+	field.runes = []rune(value)
+	field.cursor = len(field.runes)
+	field.err = nil
+}
 
-	// Check for Ctrl+C (quit)
-	if buf[0] == 3 {
-		return "quit"
-	}
-	// Check for Ctrl+D (clear)
-	if buf[0] == 4 {
-		return "clear"
+// hotkeys lists what can be pressed in the field besides editing
+func (field *FormField) hotkeys() string {
+	var hints []string
+	for _, action := range field.actions {
+		hints = append(hints, "Ctrl+"+strings.ToUpper(string(action.Key))+" "+action.Label)
 	}
-	// Check for Ctrl+S (save and quit)
-	if buf[0] == 19 {
-		return "savequit"
+	if field.IsPassword {
+		hints = append(hints, "Ctrl+R Reveal")
 	}
+	return strings.Join(hints, " | ")
+}
 
-	// Check for arrow keys
-	if buf[0] == 27 {
-		// Read next character
-		_, err = os.Stdin.Read(buf[:])
-		if err != nil {
-			return "quit"
-		}
+// value is the edited text with surrounding whitespace removed
+func (field *FormField) value() string {
+	if field.IsPassword {
+		return string(field.runes) // Spaces can be part of a passphrase
+	}
+	if field.IsMultiline {
+		return strings.Trim(string(field.runes), "\n")
+	}
+	return strings.TrimSpace(string(field.runes))
+}
 
-		if buf[0] == 91 {
-			// Read the arrow key identifier
-			_, err = os.Stdin.Read(buf[:])
-			if err != nil {
-				return "quit"
-			}
+func (field *FormField) insert(text []rune) {
+	runes := make([]rune, 0, len(field.runes)+len(text))
+	runes = append(runes, field.runes[:field.cursor]...)
+	runes = append(runes, text...)
+	runes = append(runes, field.runes[field.cursor:]...)
+	field.runes = runes
+	field.cursor += len(text)
+	field.err = nil
+}
 
-			switch buf[0] {
-			case 65: // Up arrow
-				return "up"
-			case 66: // Down arrow
-				return "down"
-			}
-		}
+func (field *FormField) remove(from, to int) {
+	from = max(from, 0)
+	to = min(to, len(field.runes))
+	if from >= to {
+		return
 	}
+	field.runes = append(field.runes[:from:from], field.runes[to:]...)
+	field.cursor = from
+	field.err = nil
+}
 
-	// Check for Enter
-	if buf[0] == 13 {
-		return "enter"
+func (field *FormField) lineStart() int {
+	index := field.cursor
+	for index > 0 && field.runes[index-1] != '\n' {
+		index--
 	}
-
-	return "none"
+	return index
 }
 
-// validateCurrentField validates and gets input for the current field
-func (form *InteractiveForm) validateCurrentField(action string) bool {
-	field := form.fields[form.current]
+func (field *FormField) lineEnd() int {
+	index := field.cursor
+	for index < len(field.runes) && field.runes[index] != '\n' {
+		index++
+	}
+	return index
+}
 
-	// Restore terminal to original state for input
-	if form.originalState != nil {
-		term.Restore(int(os.Stdin.Fd()), form.originalState)
+// wordStart finds the beginning of the word before the cursor
+func (field *FormField) wordStart() int {
+	index := field.cursor
+	for index > 0 && isSpace(field.runes[index-1]) {
+		index--
 	}
+	for index > 0 && !isSpace(field.runes[index-1]) {
+		index--
+	}
+	return index
+}
 
-	// Show current value for non-password fields
-	if field.DefaultValue != "" && !field.IsPassword {
-		fmt.Printf(
-			"\nEnter %s (current: %s): ",
-			field.Label,
-			field.DefaultValue,
-		)
-	} else {
-		fmt.Printf("\nEnter %s: ", field.Label)
+// wordEnd finds the end of the word after the cursor
+func (field *FormField) wordEnd() int {
+	index := field.cursor
+	for index < len(field.runes) && isSpace(field.runes[index]) {
+		index++
 	}
+	for index < len(field.runes) && !isSpace(field.runes[index]) {
+		index++
+	}
+	return index
+}
 
-	if action == "clear" {
-		field.Value = ""
+func isSpace(character rune) bool {
+	return character == ' ' || character == '\n' || character == '\t'
+}
+
+// moveLine moves the cursor to the same column on another line, it reports
+// false when there is no line in that direction
+func (field *FormField) moveLine(direction int) bool {
+	start := field.lineStart()
+	column := field.cursor - start
+
+	if direction < 0 {
+		if start == 0 {
+			return false
+		}
+		previousEnd := start - 1
+		field.cursor = previousEnd
+		previousStart := field.lineStart()
+		field.cursor = previousStart + min(column, previousEnd-previousStart)
 		return true
 	}
 
-	var value string
-	var err error
+	end := field.lineEnd()
+	if end == len(field.runes) {
+		return false
+	}
+	field.cursor = end + 1
+	nextEnd := field.lineEnd()
+	field.cursor = end + 1 + min(column, nextEnd-end-1)
+	return true
+}
 
-	if field.IsPassword {
-		value, err = form.readPassword()
-	} else {
-		value, err = form.readString()
+// display is what the field shows, passwords are masked per character
+func (field *FormField) display() []rune {
+	if field.IsPassword && !field.revealed {
+		return []rune(strings.Repeat("*", len(field.runes)))
 	}
+	return field.runes
+}
 
-	if err != nil {
-		// If there's an error (like Ctrl+C), quit
-		return false
+// layoutValue wraps text into rows of at most width cells and finds the cursor
+func layoutValue(text []rune, cursor int, width int) ([]string, int, int) {
+	var rows []string
+	var row []rune
+	rowWidth := 0
+	cursorRow, cursorColumn := 0, 0
+
+	flush := func() {
+		rows = append(rows, string(row))
+		row = nil
+		rowWidth = 0
 	}
 
-	// Trim whitespace
-	value = strings.TrimSpace(value)
+	for index := 0; index <= len(text); index++ {
+		if index == cursor {
+			cursorRow, cursorColumn = len(rows), rowWidth
+		}
+		if index == len(text) {
+			break
+		}
+
+		character := text[index]
+		if character == '\n' {
+			flush()
+			continue
+		}
 
-	// If user just pressed Enter without typing anything, keep the current value
-	if value == "" {
-		// Keep the current value (could be empty if field was cleared)
-		return true
+		characterWidth := RuneWidth(character)
+		if rowWidth+characterWidth > width {
+			flush()
+			if index == cursor {
+				cursorRow, cursorColumn = len(rows), 0
+			}
+		}
+		row = append(row, character)
+		rowWidth += characterWidth
 	}
+	flush()
 
-	// Validate required fields
-	if field.IsRequired && value == "" {
-		fmt.Println("This field is required. Press Enter to continue...")
-		bufio.NewReader(os.Stdin).ReadString('\n')
-		return false
+	// A cursor after a full row sits at the start of the next one
+	if cursorColumn >= width {
+		cursorRow, cursorColumn = cursorRow+1, 0
+		if cursorRow >= len(rows) {
+			rows = append(rows, "")
+		}
 	}
+	return rows, cursorRow, cursorColumn
+}
 
-	// Update the value (empty string is valid for clearing fields)
-	field.Value = value
-	return true
+// lines builds the form and returns where the cursor belongs, without a
+// focused field only the values are shown
+func (form *InteractiveForm) lines() ([]string, int, int) {
+	labelWidth := 0
+	for _, field := range form.fields {
+		labelWidth = max(labelWidth, StringWidth(field.Label))
+	}
+	prefixWidth := 2 + labelWidth + 2 // Indicator, label and ": "
+	valueWidth := max(getTerminalWidth()-prefixWidth-1, 10)
+
+	var lines []string
+	if form.current >= 0 {
+		guide := formGuide
+		if form.fields[form.current].IsMultiline {
+			guide = formMultilineGuide
+		}
+		lines = append(lines, styleFaint+truncateString(guide, getTerminalWidth()-1)+styleNormal)
+		if hotkeys := form.fields[form.current].hotkeys(); hotkeys != "" {
+			lines = append(lines, styleFaint+truncateString("This field: "+hotkeys, getTerminalWidth()-1)+styleNormal)
+		}
+		lines = append(lines, "")
+	}
+	cursorRow, cursorColumn := 0, 0
+
+	for index, field := range form.fields {
+		indicator := "  "
+		if index == form.current {
+			indicator = "> "
+		}
+
+		rows, fieldCursorRow, fieldCursorColumn := layoutValue(field.display(), field.cursor, valueWidth)
+		if index == form.current {
+			cursorRow = len(lines) + fieldCursorRow
+			cursorColumn = prefixWidth + fieldCursorColumn
+		}
+
+		for rowIndex, row := range rows {
+			prefix := strings.Repeat(" ", prefixWidth)
+			if rowIndex == 0 {
+				prefix = indicator + padRight(field.Label+":", labelWidth+1) + " "
+			}
+			lines = append(lines, prefix+row)
+		}
+
+		if field.meter != nil && len(field.runes) > 0 {
+			score, hint := field.meter(string(field.runes))
+			meter := StrengthBar(score) + fmt.Sprintf(" %d (%s)", score, StrengthLabel(score))
+			if hint != "" {
+				meter += styleFaint + " · " + hint + styleNormal
+			}
+			lines = append(lines, strings.Repeat(" ", prefixWidth)+truncateString(meter, valueWidth))
+		}
+		if field.err != nil {
+			lines = append(lines, strings.Repeat(" ", prefixWidth)+styleError+"⚠ "+field.err.Error()+styleNormal)
+		}
+	}
+
+	return lines, cursorRow, cursorColumn
 }
 
-// readPassword reads a password input with masking
-func (form *InteractiveForm) readPassword() (string, error) {
-	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
-	if err != nil {
-		return "", err
+// render redraws the form in place of the previous frame
+func (form *InteractiveForm) render() {
+	lines, cursorRow, cursorColumn := form.lines()
+
+	var frame strings.Builder
+	if form.cursorRow > 0 {
+		fmt.Fprintf(&frame, "\033[%dA", form.cursorRow)
+	}
+	frame.WriteString("\r\033[J")
+	frame.WriteString(strings.Join(lines, "\r\n"))
+
+	if form.current < 0 {
+		// Done, leave the cursor below the form so later output follows it
+		frame.WriteString("\r\n")
+		os.Stdout.WriteString(frame.String())
+		form.cursorRow = 0
+		return
+	}
+
+	// The cursor is on the last line now, bring it to the edit position
+	if up := len(lines) - 1 - cursorRow; up > 0 {
+		fmt.Fprintf(&frame, "\033[%dA", up)
 	}
-	return string(bytePassword), nil
+	frame.WriteString("\r")
+	if cursorColumn > 0 {
+		fmt.Fprintf(&frame, "\033[%dC", cursorColumn)
+	}
+
+	os.Stdout.WriteString(frame.String())
+	form.cursorRow = cursorRow
 }
 
-// readString reads a regular string input
-func (form *InteractiveForm) readString() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
+// finish draws the final values without guide or focus
+func (form *InteractiveForm) finish() {
+	for _, field := range form.fields {
+		field.Value = field.value()
+		field.err = nil
+		field.revealed = false
 	}
-	return strings.TrimSuffix(input, "\n"), nil
+	form.current = -1
+	form.render()
 }
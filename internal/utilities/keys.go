@@ -0,0 +1,231 @@
+package utilities
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// KeyKind identifies a decoded key press
+type KeyKind int
+
+const (
+	KeyRune KeyKind = iota
+	KeyEnter
+	KeyTab
+	KeyShiftTab
+	KeyBackspace
+	KeyDelete
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyCtrl  // Rune holds the lowercase letter, e.g. 'c' for Ctrl+C
+	KeyAlt   // Rune holds the key pressed together with Alt
+	KeyPaste // Text holds the pasted content
+	KeyUnknown
+)
+
+// Key is a single key press read from a terminal in raw mode
+type Key struct {
+	Kind KeyKind
+	Rune rune
+	Text string
+	Alt  bool // Alt was held for arrows, Backspace and similar keys
+}
+
+// Is reports whether the key is Ctrl plus the given lowercase letter
+func (key Key) Is(ctrl rune) bool {
+	return key.Kind == KeyCtrl && key.Rune == ctrl
+}
+
+const (
+	pasteStart = "\033[200~"
+	pasteEnd   = "\033[201~"
+)
+
+// KeyReader decodes raw terminal input into key presses
+type KeyReader struct {
+	input   io.Reader
+	pending []byte
+	keys    []Key
+}
+
+// NewKeyReader creates a key reader, input should be a terminal in raw mode
+func NewKeyReader(input io.Reader) *KeyReader {
+	return &KeyReader{input: input}
+}
+
+// Next blocks until a key is available
+func (reader *KeyReader) Next() (Key, error) {
+	for len(reader.keys) == 0 {
+		var buffer [256]byte
+		count, err := reader.input.Read(buffer[:])
+		if count > 0 {
+			reader.pending = append(reader.pending, buffer[:count]...)
+			reader.decode()
+		}
+		if err != nil && len(reader.keys) == 0 {
+			return Key{}, err
+		}
+	}
+
+	key := reader.keys[0]
+	reader.keys = reader.keys[1:]
+	return key, nil
+}
+
+// decode turns as much of the pending input as possible into keys
+func (reader *KeyReader) decode() {
+	for len(reader.pending) > 0 {
+		key, size := decodeKey(reader.pending)
+		if size == 0 {
+			return // Incomplete sequence, wait for more input
+		}
+		reader.pending = reader.pending[size:]
+		reader.keys = append(reader.keys, key)
+	}
+}
+
+// decodeKey decodes the first key in data and returns how many bytes it used,
+// zero means more bytes are needed
+func decodeKey(data []byte) (Key, int) {
+	text := string(data)
+
+	if strings.HasPrefix(text, pasteStart) {
+		end := strings.Index(text, pasteEnd)
+		if end < 0 {
+			return Key{}, 0
+		}
+		return Key{Kind: KeyPaste, Text: text[len(pasteStart):end]}, end + len(pasteEnd)
+	}
+
+	switch data[0] {
+	case '\r', '\n':
+		return Key{Kind: KeyEnter}, 1
+	case '\t':
+		return Key{Kind: KeyTab}, 1
+	case 127, 8:
+		return Key{Kind: KeyBackspace}, 1
+	case 27:
+		return decodeEscape(data)
+	}
+
+	if data[0] < 32 {
+		return Key{Kind: KeyCtrl, Rune: rune('a' + data[0] - 1)}, 1
+	}
+
+	if !utf8.FullRune(data) {
+		return Key{}, 0
+	}
+	character, size := utf8.DecodeRune(data)
+	return Key{Kind: KeyRune, Rune: character}, size
+}
+
+var escapeSequences = map[string]Key{
+	"[A": {Kind: KeyUp}, "OA": {Kind: KeyUp},
+	"[B": {Kind: KeyDown}, "OB": {Kind: KeyDown},
+	"[C": {Kind: KeyRight}, "OC": {Kind: KeyRight},
+	"[D": {Kind: KeyLeft}, "OD": {Kind: KeyLeft},
+	"[H": {Kind: KeyHome}, "OH": {Kind: KeyHome}, "[1~": {Kind: KeyHome}, "[7~": {Kind: KeyHome},
+	"[F": {Kind: KeyEnd}, "OF": {Kind: KeyEnd}, "[4~": {Kind: KeyEnd}, "[8~": {Kind: KeyEnd},
+	"[3~": {Kind: KeyDelete},
+	"[5~": {Kind: KeyPageUp},
+	"[6~": {Kind: KeyPageDown},
+	"[Z":  {Kind: KeyShiftTab},
+	// Alt and Ctrl modified arrows move by word in most terminals
+	"[1;3C": {Kind: KeyRight, Alt: true}, "[1;5C": {Kind: KeyRight, Alt: true},
+	"[1;3D": {Kind: KeyLeft, Alt: true}, "[1;5D": {Kind: KeyLeft, Alt: true},
+}
+
+func decodeEscape(data []byte) (Key, int) {
+	if len(data) == 1 {
+		// A lone escape, terminals send whole sequences in a single write
+		return Key{Kind: KeyEscape}, 1
+	}
+
+	switch data[1] {
+	case '[', 'O':
+		// CSI: parameters and intermediates, then a final byte in 0x40-0x7e
+		for index := 2; index < len(data); index++ {
+			if data[index] >= 0x40 && data[index] <= 0x7e {
+				sequence := string(data[1 : index+1])
+				if key, ok := escapeSequences[sequence]; ok {
+					return key, index + 1
+				}
+				return Key{Kind: KeyUnknown, Text: sequence}, index + 1
+			}
+			if data[1] == 'O' {
+				break
+			}
+		}
+		if data[1] == 'O' && len(data) > 2 {
+			return Key{Kind: KeyUnknown}, 3
+		}
+		return Key{}, 0
+	case 127, 8:
+		return Key{Kind: KeyBackspace, Alt: true}, 2
+	case 27:
+		return Key{Kind: KeyEscape}, 1
+	}
+
+	if !utf8.FullRune(data[1:]) {
+		return Key{}, 0
+	}
+	character, size := utf8.DecodeRune(data[1:])
+	return Key{Kind: KeyAlt, Rune: character}, size + 1
+}
+
+// KeyResult is a key, or the error that ended reading, delivered by a KeyPump
+type KeyResult struct {
+	Key Key
+	Err error
+}
+
+// KeyPump reads keys in the background, one at a time and only on request,
+// so no read is pending while another part of the program owns the terminal
+type KeyPump struct {
+	requests chan struct{}
+	results  chan KeyResult
+	pending  bool
+}
+
+// NewKeyPump starts a background reader, call Close when done
+func NewKeyPump(reader *KeyReader) *KeyPump {
+	pump := &KeyPump{
+		requests: make(chan struct{}),
+		results:  make(chan KeyResult, 1), // A late result must not block the reader after Close
+	}
+	go func() {
+		for range pump.requests {
+			key, err := reader.Next()
+			pump.results <- KeyResult{Key: key, Err: err}
+		}
+	}()
+	return pump
+}
+
+// Next asks for a key unless a request is already pending and returns the channel it arrives on
+func (pump *KeyPump) Next() <-chan KeyResult {
+	if !pump.pending {
+		pump.requests <- struct{}{}
+		pump.pending = true
+	}
+	return pump.results
+}
+
+// Take unpacks a result received from Next and allows the next request
+func (pump *KeyPump) Take(result KeyResult) (Key, error) {
+	pump.pending = false
+	return result.Key, result.Err
+}
+
+// Close stops the background reader once its pending read, if any, finishes
+func (pump *KeyPump) Close() {
+	close(pump.requests)
+}
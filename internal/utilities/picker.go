@@ -0,0 +1,227 @@
+package utilities
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"passenger-go-cli/internal/fuzzy"
+	"passenger-go-cli/internal/schemas"
+
+	"golang.org/x/term"
+)
+
+// ErrPickerCancelled is returned when the user leaves the picker without choosing
+var ErrPickerCancelled = errors.New("no account selected")
+
+const (
+	pickerMaxRows     = 8
+	pickerPreviewRows = 6
+	highlightStart    = "\033[1;33m"
+	highlightEnd      = "\033[0m"
+)
+
+// accountPicker draws a live filtered list of accounts below the cursor
+type accountPicker struct {
+	accounts []schemas.Account
+	matches  []fuzzy.AccountMatch
+	query    []rune
+	selected int
+	offset   int
+	prompt   string
+	output   *os.File
+}
+
+// PickAccount lets the user choose an account by typing to filter and using
+// the arrow keys. It draws on stderr so stdout stays clean for piping.
+func PickAccount(accounts []schemas.Account, prompt string) (*schemas.Account, error) {
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no accounts to choose from")
+	}
+	if !IsTerminal(os.Stdin) || !IsTerminal(os.Stderr) {
+		return nil, fmt.Errorf("picking an account needs an interactive terminal")
+	}
+
+	picker := &accountPicker{
+		accounts: accounts,
+		prompt:   prompt,
+		output:   os.Stderr,
+	}
+	picker.filter()
+
+	originalState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set terminal raw mode: %w", err)
+	}
+	defer func() {
+		picker.clear()
+		term.Restore(int(os.Stdin.Fd()), originalState)
+	}()
+
+	resized, terminated, stopSignals := WatchTerminalSignals()
+	defer stopSignals()
+
+	keys := NewKeyPump(NewKeyReader(os.Stdin))
+	defer keys.Close()
+
+	for {
+		picker.render()
+
+		var key Key
+		select {
+		case <-resized:
+			continue
+		case <-terminated:
+			return nil, fmt.Errorf("picker terminated")
+		case result := <-keys.Next():
+			key, err = keys.Take(result)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		switch {
+		case key.Kind == KeyEnter:
+			if len(picker.matches) > 0 {
+				chosen := picker.matches[picker.selected].Account
+				return &chosen, nil
+			}
+		case key.Kind == KeyEscape, key.Is('c'), key.Is('d') && len(picker.query) == 0:
+			return nil, ErrPickerCancelled
+		case key.Kind == KeyUp, key.Is('p'), key.Kind == KeyShiftTab:
+			picker.move(-1)
+		case key.Kind == KeyDown, key.Is('n'), key.Kind == KeyTab:
+			picker.move(1)
+		case key.Kind == KeyPageUp:
+			picker.move(-pickerMaxRows)
+		case key.Kind == KeyPageDown:
+			picker.move(pickerMaxRows)
+		case key.Kind == KeyBackspace && key.Alt, key.Is('w'):
+			picker.query = []rune(strings.TrimRightFunc(
+				strings.TrimRight(string(picker.query), " "),
+				func(r rune) bool { return r != ' ' },
+			))
+			picker.filter()
+		case key.Kind == KeyBackspace:
+			if len(picker.query) > 0 {
+				picker.query = picker.query[:len(picker.query)-1]
+				picker.filter()
+			}
+		case key.Is('u'):
+			picker.query = nil
+			picker.filter()
+		case key.Kind == KeyRune:
+			picker.query = append(picker.query, key.Rune)
+			picker.filter()
+		case key.Kind == KeyPaste:
+			picker.query = append(picker.query, []rune(strings.ReplaceAll(key.Text, "\n", " "))...)
+			picker.filter()
+		}
+	}
+}
+
+func (picker *accountPicker) filter() {
+	if strings.TrimSpace(string(picker.query)) == "" {
+		picker.matches = make([]fuzzy.AccountMatch, len(picker.accounts))
+		for index, account := range picker.accounts {
+			picker.matches[index] = fuzzy.AccountMatch{Account: account}
+		}
+	} else {
+		picker.matches = fuzzy.RankAccounts(string(picker.query), picker.accounts)
+	}
+	picker.selected = 0
+	picker.offset = 0
+}
+
+func (picker *accountPicker) move(delta int) {
+	if len(picker.matches) == 0 {
+		return
+	}
+	picker.selected = min(max(picker.selected+delta, 0), len(picker.matches)-1)
+}
+
+func (picker *accountPicker) rows() int {
+	_, height, err := term.GetSize(int(picker.output.Fd()))
+	if err != nil {
+		height = 24
+	}
+	// Prompt, counter, separator and preview share the screen with the list
+	return max(min(pickerMaxRows, height-pickerPreviewRows-4), 1)
+}
+
+func (picker *accountPicker) lines() []string {
+	width := max(getTerminalWidth()-1, 20)
+	rows := picker.rows()
+
+	if picker.selected < picker.offset {
+		picker.offset = picker.selected
+	}
+	if picker.selected >= picker.offset+rows {
+		picker.offset = picker.selected - rows + 1
+	}
+
+	lines := []string{
+		picker.prompt + "> " + string(picker.query),
+		"\033[2m  " + strconv.Itoa(len(picker.matches)) + "/" + strconv.Itoa(len(picker.accounts)) + "\033[0m",
+	}
+
+	for index := picker.offset; index < min(picker.offset+rows, len(picker.matches)); index++ {
+		match := picker.matches[index]
+		marker := "  "
+		if index == picker.selected {
+			marker = "\033[7m>\033[0m "
+		}
+		line := marker +
+			fuzzy.Highlight(match.Account.Platform, match.Platform, highlightStart, highlightEnd) + "  " +
+			fuzzy.Highlight(match.Account.Identifier, match.Identifier, highlightStart, highlightEnd) + "  " +
+			"\033[2m" + fuzzy.Highlight(match.Account.URL, match.URL, highlightStart, highlightEnd+"\033[2m") + "\033[0m"
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "\033[2m"+strings.Repeat("─", min(width, 40))+"\033[0m")
+	if len(picker.matches) > 0 {
+		lines = append(lines, accountPreview(picker.matches[picker.selected].Account)...)
+	}
+
+	for index, line := range lines {
+		lines[index] = truncateString(line, width)
+	}
+	return lines
+}
+
+// accountPreview shows the details of an account in a few lines
+func accountPreview(account schemas.Account) []string {
+	notes := strings.ReplaceAll(account.Notes, "\n", " ⏎ ")
+	if notes == "" {
+		notes = "<no-notes-available>"
+	}
+	return []string{
+		"ID:         " + account.ID,
+		"Platform:   " + account.Platform,
+		"Identifier: " + account.Identifier,
+		"URL:        " + account.URL,
+		"Notes:      " + notes,
+		"Strength:   " + strconv.Itoa(account.Strength) + " (" + StrengthLabel(account.Strength) + ")",
+	}
+}
+
+// render redraws the picker, leaving the cursor at the end of the query
+func (picker *accountPicker) render() {
+	lines := picker.lines()
+
+	var frame strings.Builder
+	frame.WriteString("\r\033[J")
+	frame.WriteString(strings.Join(lines, "\r\n"))
+	if len(lines) > 1 {
+		fmt.Fprintf(&frame, "\033[%dA", len(lines)-1)
+	}
+	fmt.Fprintf(&frame, "\r\033[%dC", max(displayWidth(lines[0]), 1))
+	picker.output.WriteString(frame.String())
+}
+
+// clear removes the picker from the screen
+func (picker *accountPicker) clear() {
+	picker.output.WriteString("\r\033[J")
+}
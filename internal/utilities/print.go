@@ -4,18 +4,88 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/term"
 )
 
+// escapeLength returns the byte length of the ANSI escape sequence at the start of s, or 0
+func escapeLength(s string) int {
+	if len(s) < 2 || s[0] != '\033' || s[1] != '[' {
+		return 0
+	}
+	for index := 2; index < len(s); index++ {
+		if s[index] >= 0x40 && s[index] <= 0x7e {
+			return index + 1
+		}
+	}
+	return len(s)
+}
+
+// displayWidth returns the number of terminal cells s occupies, ignoring ANSI escapes
+func displayWidth(s string) int {
+	width := 0
+	for index := 0; index < len(s); {
+		if skip := escapeLength(s[index:]); skip > 0 {
+			index += skip
+			continue
+		}
+		character, size := utf8.DecodeRuneInString(s[index:])
+		index += size
+		width += RuneWidth(character)
+	}
+	return width
+}
+
+// padRight pads s with spaces until it fills width terminal cells
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-displayWidth(s), 0))
+}
+
+// DisplayWidth returns the number of terminal cells s occupies
+func DisplayWidth(s string) int {
+	return displayWidth(s)
+}
+
+// FitWidth truncates or pads s so it fills exactly width terminal cells
+func FitWidth(s string, width int) string {
+	return padRight(truncateString(s, width), width)
+}
+
 func truncateString(s string, maxLength int) string {
-	if len(s) <= maxLength {
+	if displayWidth(s) <= maxLength {
 		return s
 	}
+
+	suffix := "..."
 	if maxLength <= 3 {
-		return s[:maxLength]
+		suffix = ""
+	}
+	limit := maxLength - len(suffix)
+
+	var result strings.Builder
+	hasEscapes := false
+	width := 0
+	for index := 0; index < len(s); {
+		// Keep escape sequences so colors are not cut in half
+		if skip := escapeLength(s[index:]); skip > 0 {
+			result.WriteString(s[index : index+skip])
+			hasEscapes = true
+			index += skip
+			continue
+		}
+		character, size := utf8.DecodeRuneInString(s[index:])
+		if width+RuneWidth(character) > limit {
+			break
+		}
+		result.WriteString(s[index : index+size])
+		index += size
+		width += RuneWidth(character)
+	}
+	if hasEscapes {
+		result.WriteString("\033[0m")
 	}
-	return s[:maxLength-3] + "..."
+	return result.String() + suffix
 }
 
 func getTerminalWidth() int {
@@ -58,7 +128,7 @@ func PrintTable[T any](data []T, headers []string, useStderr ...bool) {
 				maxColumns = len(rowData)
 			}
 			if len(rowData) >= 2 {
-				keyLength := len(rowData[0])
+				keyLength := displayWidth(rowData[0])
 				if keyLength > keyColumnMaxWidth {
 					keyColumnMaxWidth = keyLength
 				}
@@ -89,7 +159,7 @@ func PrintTable[T any](data []T, headers []string, useStderr ...bool) {
 				if len(rowData) >= 2 {
 					truncatedKey := truncateString(rowData[0], keyWidth)
 					truncatedValue := truncateString(rowData[1], valueWidth)
-					fmt.Fprintf(output, "%-*s | %s\n", keyWidth, truncatedKey, truncatedValue)
+					fmt.Fprintf(output, "%s | %s\n", padRight(truncatedKey, keyWidth), truncatedValue)
 				}
 			}
 		} else {
@@ -100,8 +170,8 @@ func PrintTable[T any](data []T, headers []string, useStderr ...bool) {
 			for _, row := range data {
 				rowData := any(row).([]string)
 				for cellIndex, cell := range rowData {
-					if cellIndex < len(maxWidths) && len(cell) > maxWidths[cellIndex] {
-						maxWidths[cellIndex] = len(cell)
+					if cellIndex < len(maxWidths) && displayWidth(cell) > maxWidths[cellIndex] {
+						maxWidths[cellIndex] = displayWidth(cell)
 					}
 				}
 			}
@@ -139,7 +209,7 @@ func PrintTable[T any](data []T, headers []string, useStderr ...bool) {
 				for index, cell := range rowData {
 					if index < len(maxWidths) {
 						truncatedCell := truncateString(cell, maxWidths[index])
-						fmt.Fprintf(output, "%-*s", maxWidths[index], truncatedCell)
+						fmt.Fprint(output, padRight(truncatedCell, maxWidths[index]))
 						if index < len(maxWidths)-1 {
 							fmt.Fprint(output, " | ")
 						}
@@ -154,15 +224,15 @@ func PrintTable[T any](data []T, headers []string, useStderr ...bool) {
 	// Calculate initial max widths
 	maxWidths := make([]int, len(headers))
 	for index, header := range headers {
-		maxWidths[index] = len(header)
+		maxWidths[index] = displayWidth(header)
 	}
 
 	// Check data rows for maximum width
 	for index := range data {
 		row := any(data[index]).([]string)
 		for cellIndex, cell := range row {
-			if cellIndex < len(maxWidths) && len(cell) > maxWidths[cellIndex] {
-				maxWidths[cellIndex] = len(cell)
+			if cellIndex < len(maxWidths) && displayWidth(cell) > maxWidths[cellIndex] {
+				maxWidths[cellIndex] = displayWidth(cell)
 			}
 		}
 	}
@@ -197,7 +267,7 @@ func PrintTable[T any](data []T, headers []string, useStderr ...bool) {
 	// Print headers with proper padding
 	for index, header := range headers {
 		truncatedHeader := truncateString(header, maxWidths[index])
-		fmt.Fprintf(output, "%-*s", maxWidths[index], truncatedHeader)
+		fmt.Fprint(output, padRight(truncatedHeader, maxWidths[index]))
 		if index < len(headers)-1 {
 			fmt.Fprint(output, " | ")
 		}
@@ -220,7 +290,7 @@ func PrintTable[T any](data []T, headers []string, useStderr ...bool) {
 		for index, cell := range rowData {
 			if index < len(maxWidths) {
 				truncatedCell := truncateString(cell, maxWidths[index])
-				fmt.Fprintf(output, "%-*s", maxWidths[index], truncatedCell)
+				fmt.Fprint(output, padRight(truncatedCell, maxWidths[index]))
 				if index < len(headers)-1 {
 					fmt.Fprint(output, " | ")
 				}
@@ -229,3 +299,13 @@ func PrintTable[T any](data []T, headers []string, useStderr ...bool) {
 		fmt.Fprintln(output)
 	}
 }
+
+// MaskPassphrase hides all but the first and last two characters of longer
+// passphrases, enough to tell two apart without revealing them
+func MaskPassphrase(passphrase string) string {
+	runes := []rune(passphrase)
+	if len(runes) < 12 {
+		return strings.Repeat("•", len(runes))
+	}
+	return string(runes[:2]) + strings.Repeat("•", len(runes)-4) + string(runes[len(runes)-2:])
+}
@@ -0,0 +1,57 @@
+//go:build linux
+
+package utilities
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// recordingPrograms record the terminal of the processes they start
+var recordingPrograms = []string{"script", "asciinema"}
+
+// recordingParent returns the name of a recording program this process runs
+// under, found by walking up the parent processes in /proc. "" when none is.
+func recordingParent() string {
+	pid := os.Getppid()
+	// The limit only guards against a loop while processes come and go
+	for range 64 {
+		if pid <= 1 {
+			return ""
+		}
+		name, parent, ok := processInfo(pid)
+		if !ok {
+			return ""
+		}
+		if slices.Contains(recordingPrograms, name) {
+			return name
+		}
+		pid = parent
+	}
+	return ""
+}
+
+// processInfo reads the name and parent PID from /proc/<pid>/stat, which is
+// "pid (name) state ppid ...". The name may contain spaces and parentheses.
+func processInfo(pid int) (string, int, bool) {
+	content, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return "", 0, false
+	}
+	stat := string(content)
+	start, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+	if start < 0 || end < start {
+		return "", 0, false
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 2 {
+		return "", 0, false
+	}
+	parent, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, false
+	}
+	return stat[start+1 : end], parent, true
+}
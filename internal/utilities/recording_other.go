@@ -0,0 +1,8 @@
+//go:build !linux
+
+package utilities
+
+// recordingParent only knows how to look at parent processes on Linux
+func recordingParent() string {
+	return ""
+}
@@ -0,0 +1,67 @@
+package utilities
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// ErrRevealInterrupted is returned when a signal ended the countdown
+var ErrRevealInterrupted = errors.New("interrupted, the secret was hidden")
+
+// Reveal shows a secret on the alternate screen with a countdown, then clears
+// it and returns to the normal screen, so it never reaches the scrollback.
+// Any key hides it early, a signal hides it and returns ErrRevealInterrupted.
+func Reveal(label, secret string, duration time.Duration) error {
+	if !IsTerminal(os.Stdin) || !IsTerminal(os.Stdout) {
+		return fmt.Errorf("revealing needs an interactive terminal")
+	}
+
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to set terminal raw mode: %w", err)
+	}
+	os.Stdout.WriteString("\033[?1049h\033[?25l")
+	// Clear the alternate screen before leaving, some terminals keep it
+	// around. Only that screen, the scrollback belongs to the user.
+	defer func() {
+		os.Stdout.WriteString("\033[2J\033[H\033[?25h\033[?1049l")
+		term.Restore(int(os.Stdin.Fd()), state)
+	}()
+
+	resized, terminated, stopSignals := WatchTerminalSignals()
+	defer stopSignals()
+
+	keys := NewKeyPump(NewKeyReader(os.Stdin))
+	defer keys.Close()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	// Raw mode does not return the cursor on a line feed, as with several passphrases
+	lines := strings.ReplaceAll(secret, "\n", "\r\n  ")
+
+	deadline := time.Now().Add(duration)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil
+		}
+		seconds := int((remaining + time.Second - 1) / time.Second)
+		os.Stdout.WriteString(fmt.Sprintf("\033[2J\033[H%s\r\n\r\n  %s\r\n\r\nHiding in %ds, press any key to hide now",
+			label, lines, seconds))
+
+		select {
+		case <-ticker.C:
+		case <-resized:
+		case <-terminated:
+			return ErrRevealInterrupted
+		case <-keys.Next():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package utilities
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// recordingVariables are set in sessions recorded by asciinema
+var recordingVariables = []string{"ASCIINEMA_REC"}
+
+// SecretOutput is how a command hands out a secret. On a terminal it must be
+// revealed or copied so it stays out of the scrollback, piped it is written
+// as is so scripts get the exact bytes.
+type SecretOutput struct {
+	Copy    func(label, secret string) error // Puts the secret on the clipboard, nil unless asked to
+	Reveal  time.Duration                    // Shows the secret on the alternate screen this long
+	Newline bool                             // Ends piped output with a line break
+}
+
+// Check reports whether a secret can be written, call it before creating
+// secrets that cannot be fetched again. label names the secret in messages.
+func (output SecretOutput) Check(label string) error {
+	switch {
+	case output.Copy != nil:
+		return nil
+	case output.Reveal > 0:
+		if !IsTerminal(os.Stdin) || !IsTerminal(os.Stdout) {
+			return fmt.Errorf("--reveal needs an interactive terminal")
+		}
+		return nil
+	case IsTerminal(os.Stdout):
+		return fmt.Errorf("stdout is a terminal where %s would stay in the scrollback, "+
+			"use --reveal <seconds> or --copy, or pipe it", label)
+	}
+	return nil
+}
+
+// Write hands out secret following Check
+func (output SecretOutput) Write(label, secret string) error {
+	if err := output.Check(label); err != nil {
+		return err
+	}
+	if output.Copy != nil {
+		return output.Copy(label, secret)
+	}
+
+	if reason := RecordedSession(); reason != "" {
+		os.Stderr.WriteString("⚠️ This session looks recorded (" + reason + "), the recording may keep " + label + "\n")
+	}
+
+	if output.Reveal > 0 {
+		return Reveal(strings.ToUpper(label[:1])+label[1:], secret, output.Reveal)
+	}
+	if output.Newline {
+		secret += "\n"
+	}
+	_, err := os.Stdout.WriteString(secret)
+	return err
+}
+
+// RecordedSession tells why the terminal session looks recorded, "" when it
+// does not. script(1) sets no variable, on Linux it is found among the parent
+// processes, elsewhere only asciinema is noticed.
+func RecordedSession() string {
+	for _, variable := range recordingVariables {
+		if os.Getenv(variable) != "" {
+			return variable + " is set"
+		}
+	}
+	if program := recordingParent(); program != "" {
+		return "it runs under " + program
+	}
+	return ""
+}
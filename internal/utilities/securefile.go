@@ -0,0 +1,67 @@
+package utilities
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// SecureTempDir creates a private directory for files holding secrets. It
+// prefers memory backed filesystems so nothing reaches the disk, the second
+// return value tells whether that was possible.
+func SecureTempDir() (string, bool, error) {
+	var candidates []string
+	if runtime.GOOS == "linux" {
+		candidates = append(candidates, "/dev/shm")
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		candidates = append(candidates, runtimeDir)
+	}
+
+	for _, candidate := range candidates {
+		directory, err := os.MkdirTemp(candidate, "passenger-go-")
+		if err == nil {
+			return directory, true, nil
+		}
+	}
+
+	directory, err := os.MkdirTemp("", "passenger-go-")
+	return directory, false, err
+}
+
+// ShredDir overwrites every file in directory with zeros and removes it.
+// Editors leave backup and swap files next to the edited file, those are
+// caught as well.
+func ShredDir(directory string) error {
+	filepath.WalkDir(directory, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && entry.Type().IsRegular() {
+			shredFile(path)
+		}
+		return nil
+	})
+	return os.RemoveAll(directory)
+}
+
+func shredFile(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	zeros := make([]byte, 4096)
+	for remaining := info.Size(); remaining > 0; {
+		chunk := min(remaining, int64(len(zeros)))
+		written, err := file.Write(zeros[:chunk])
+		if err != nil {
+			return
+		}
+		remaining -= int64(written)
+	}
+	file.Sync()
+}
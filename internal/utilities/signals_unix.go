@@ -0,0 +1,22 @@
+//go:build !windows
+
+package utilities
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchTerminalSignals reports terminal resizes and requests to terminate
+func WatchTerminalSignals() (<-chan os.Signal, <-chan os.Signal, func()) {
+	resized := make(chan os.Signal, 1)
+	terminated := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	signal.Notify(terminated, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGINT)
+
+	return resized, terminated, func() {
+		signal.Stop(resized)
+		signal.Stop(terminated)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build windows
+
+package utilities
+
+import (
+	"os"
+	"os/signal"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// WatchTerminalSignals reports terminal resizes and requests to terminate. Windows
+// has no SIGWINCH, so the console size is polled instead.
+func WatchTerminalSignals() (<-chan os.Signal, <-chan os.Signal, func()) {
+	resized := make(chan os.Signal, 1)
+	terminated := make(chan os.Signal, 1)
+	signal.Notify(terminated, os.Interrupt)
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+
+		width, height, _ := term.GetSize(int(os.Stdout.Fd()))
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				newWidth, newHeight, _ := term.GetSize(int(os.Stdout.Fd()))
+				if newWidth != width || newHeight != height {
+					width, height = newWidth, newHeight
+					select {
+					case resized <- nil: // Only the event matters, not the value
+					default:
+					}
+				}
+			}
+		}
+	}()
+
+	return resized, terminated, func() {
+		signal.Stop(terminated)
+		close(done)
+	}
+}
@@ -0,0 +1,122 @@
+package utilities
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"passenger-go-cli/internal/config"
+)
+
+/**
+ * Named templates are stored in the config directory:
+ * - Linux: ~/.config/passenger-go/templates/<name>.tmpl
+ * - macOS: ~/Library/Application Support/passenger-go/templates/<name>.tmpl
+ * - Windows: %APPDATA%/passenger-go/templates/<name>.tmpl
+ */
+
+// templateFunctions are the helpers available inside --format templates
+var templateFunctions = template.FuncMap{
+	"upper": strings.ToUpper,
+	"truncate": func(length int, value string) string {
+		return truncateString(value, length)
+	},
+	"json": func(value any) (string, error) {
+		var encoded strings.Builder
+		encoder := json.NewEncoder(&encoded)
+		encoder.SetEscapeHTML(false)
+		err := encoder.Encode(value)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(encoded.String(), "\n"), nil
+	},
+	"pad": func(width int, value string) string {
+		if width < 0 {
+			return fmt.Sprintf("%*s", -width, value)
+		}
+		return fmt.Sprintf("%-*s", width, value)
+	},
+	"strength": StrengthLabel,
+}
+
+// StrengthLabel turns a strength score between 0 and 100 into a word
+func StrengthLabel(strength int) string {
+	switch {
+	case strength < 40:
+		return "weak"
+	case strength < 70:
+		return "moderate"
+	case strength < 90:
+		return "strong"
+	default:
+		return "very strong"
+	}
+}
+
+// StrengthBar draws a strength score between 0 and 100 as ten colored cells
+func StrengthBar(strength int) string {
+	filled := min(max(strength/10, 0), 10)
+	color := "\033[31m"
+	switch {
+	case strength >= 70:
+		color = "\033[32m"
+	case strength >= 40:
+		color = "\033[33m"
+	}
+	return color + strings.Repeat("■", filled) + styleFaint + strings.Repeat("□", 10-filled) + styleNormal
+}
+
+// ParseTemplate parses an inline template or loads a named one from the config directory
+func ParseTemplate(format string) (*template.Template, error) {
+	source := format
+
+	if !strings.Contains(format, "{{") {
+		// A name, not a path, so it cannot point outside the templates directory
+		if strings.ContainsAny(format, `/\`) || strings.Contains(format, "..") {
+			return nil, fmt.Errorf("template name %q must not contain path separators or ..", format)
+		}
+
+		directory, err := config.GetConfigDir()
+		if err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(directory, "templates", format+".tmpl")
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("template %q not found in %s", format, filepath.Dir(path))
+		}
+		source = strings.TrimSuffix(string(content), "\n")
+	} else {
+		// Shells pass \t and \n literally, users expect them to be escapes
+		source = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(source)
+	}
+
+	parsed, err := template.New("format").Funcs(templateFunctions).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	return parsed, nil
+}
+
+// PrintTemplate renders each item with the given format, one item per line
+func PrintTemplate[T any](output io.Writer, format string, items ...T) error {
+	parsed, err := ParseTemplate(format)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		err = parsed.Execute(output, item)
+		if err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
+		}
+		fmt.Fprintln(output)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package utilities
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether the file is attached to a terminal
+func IsTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// UseColor reports whether ANSI colors should be written to the file
+func UseColor(file *os.File) bool {
+	_, disabled := os.LookupEnv("NO_COLOR")
+	return !disabled && IsTerminal(file)
+}
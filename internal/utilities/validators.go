@@ -0,0 +1,46 @@
+package utilities
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator checks a form value and returns a message for the user when it is not acceptable
+type Validator func(value string) error
+
+// ValidateRequired rejects empty and whitespace only values
+func ValidateRequired(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("this field is required")
+	}
+	return nil
+}
+
+// ValidateURL accepts empty values, absolute URLs like https://example.com and
+// bare hosts like example.com, which many accounts are saved with
+func ValidateURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	candidate := value
+	if !strings.Contains(candidate, "://") {
+		candidate = "https://" + candidate
+	}
+	parsed, err := url.Parse(candidate)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" || strings.ContainsAny(value, " \t\n") {
+		return fmt.Errorf("must be a URL like https://example.com or a host like example.com")
+	}
+	return nil
+}
+
+// ValidateMaxLength limits the number of characters, not bytes
+func ValidateMaxLength(length int) Validator {
+	return func(value string) error {
+		if utf8.RuneCountInString(value) > length {
+			return fmt.Errorf("must be at most %d characters", length)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package utilities
+
+import "unicode"
+
+// wideRanges are East Asian wide and fullwidth blocks plus emoji, which take two cells
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},
+	{0x231A, 0x231B},
+	{0x2329, 0x232A},
+	{0x23E9, 0x23EC},
+	{0x25FD, 0x25FE},
+	{0x2614, 0x2615},
+	{0x26A1, 0x26A1},
+	{0x26AA, 0x26AB},
+	{0x26BD, 0x26BE},
+	{0x26C4, 0x26C5},
+	{0x26D4, 0x26D4},
+	{0x26EA, 0x26EA},
+	{0x26F2, 0x26F5},
+	{0x26FA, 0x26FD},
+	{0x2705, 0x2705},
+	{0x270A, 0x270B},
+	{0x2728, 0x2728},
+	{0x274C, 0x274C},
+	{0x2753, 0x2757},
+	{0x2795, 0x2797},
+	{0x27B0, 0x27B0},
+	{0x2B1B, 0x2B1C},
+	{0x2B50, 0x2B55},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xA960, 0xA97F},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F004, 0x1F004},
+	{0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A},
+	{0x1F200, 0x1F251},
+	{0x1F300, 0x1F64F},
+	{0x1F680, 0x1F6FF},
+	{0x1F900, 0x1F9FF},
+	{0x1FA70, 0x1FAFF},
+	{0x20000, 0x2FFFD},
+	{0x30000, 0x3FFFD},
+}
+
+// RuneWidth returns how many terminal cells a rune occupies
+func RuneWidth(character rune) int {
+	switch {
+	case character == 0:
+		return 0
+	case character < 32 || (character >= 0x7f && character < 0xa0):
+		return 0
+	case character == 0x200D || (character >= 0xFE00 && character <= 0xFE0F):
+		return 0 // Zero width joiner and variation selectors
+	case unicode.Is(unicode.Mn, character) || unicode.Is(unicode.Me, character) || unicode.Is(unicode.Cf, character):
+		return 0
+	case character < 0x1100:
+		return 1
+	}
+
+	for _, block := range wideRanges {
+		if character < block[0] {
+			break
+		}
+		if character <= block[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// StringWidth returns how many terminal cells a plain string occupies
+func StringWidth(text string) int {
+	width := 0
+	for _, character := range text {
+		width += RuneWidth(character)
+	}
+	return width
+}
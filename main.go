@@ -18,15 +18,27 @@ func main() {
 			cmd.RegisterCommand(),
 			cmd.ValidateCommand(),
 			cmd.ListCommand(),
+			cmd.SearchCommand(),
+			cmd.TUICommand(),
 			cmd.GetCommand(),
 			cmd.PassphraseCommand(),
 			cmd.ChangeMasterPassphraseCommand(),
 			cmd.GenerateCommand(),
 			cmd.AlternateCommand(),
+			cmd.RulesCommand(),
+			cmd.AuditCommand(),
+			cmd.BreachCheckCommand(),
+			cmd.OtpCommand(),
 			cmd.CreateCommand(),
 			cmd.UpdateCommand(),
+			cmd.RotateCommand(),
+			cmd.HooksCommand(),
+			cmd.DueCommand(),
+			cmd.EditCommand(),
+			cmd.DeleteCommand(),
 			cmd.ExportCommand(),
 			cmd.ImportCommand(),
+			cmd.ClipboardClearCommand(),
 		},
 		EnableBashCompletion: true,
 	}